@@ -1,7 +1,14 @@
 package ecl
 
 import (
+	"context"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/go-logr/logr"
 	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/message"
 	"github.com/jhseong7/ecl/stream"
 	"github.com/jhseong7/ecl/style"
 )
@@ -15,12 +22,21 @@ type (
 	LogLevel = logger.LogLevel
 
 	LogStyle = style.LogStyle
+
+	Field = message.Field
+
+	SlogHandler = logger.SlogHandler
+
+	ContextExtractor      = logger.ContextExtractor
+	TraceContextExtractor = logger.TraceContextExtractor
 )
 
 const (
 	NestJsStyle  = style.NestJsStyle
 	SpringStyle  = style.SpringStyle
 	DefaultStyle = style.DefaultStyle
+	JsonStyle    = style.JsonStyle
+	LogfmtStyle  = style.LogfmtStyle
 
 	All   = logger.All
 	Trace = logger.Trace
@@ -50,3 +66,100 @@ func SetLogStyle(style LogStyle) {
 func SetLogLevel(level LogLevel) {
 	logger.SetLogLevel(level)
 }
+
+// Create a log/slog handler that writes to the ecl streams with the ecl styles
+func NewSlogHandler(o LoggerOption) *SlogHandler {
+	return logger.NewSlogHandler(o)
+}
+
+// Get a Logger that writes to the slog.Logger. The logger name is added as the "logger" attr
+func FromSlog(sl *slog.Logger) Logger {
+	return logger.FromSlog(sl)
+}
+
+// Create a logr.Logger that writes through the logger. V(0) is Info, V(1) is Debug and V(2) or above is Trace
+func NewLogr(l Logger) logr.Logger {
+	return logger.NewLogr(l)
+}
+
+// Redirect the output of the standard library log package to the logger with the level
+func RedirectStdLog(l Logger, level LogLevel) {
+	logger.RedirectStdLog(l, level)
+}
+
+// Create a standard library log.Logger that writes through the logger. (e.g. for http.Server.ErrorLog)
+func NewStdLog(l Logger, level LogLevel) *log.Logger {
+	return logger.NewStdLog(l, level)
+}
+
+// Add an extractor of the fields (e.g. request ID, user ID) applied by Logger.Ctx to all loggers
+func AddContextExtractor(e ContextExtractor) {
+	logger.AddContextExtractor(e)
+}
+
+// Set the extractor of the trace and span IDs applied by Logger.Ctx. See the ecl/otel package for OpenTelemetry
+func SetTraceContextExtractor(e TraceContextExtractor) {
+	logger.SetTraceContextExtractor(e)
+}
+
+// Get a copy of the context that carries the logger
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return logger.NewContext(ctx, l)
+}
+
+// Get the logger carried by the context. If there is none, a default logger is returned
+func FromContext(ctx context.Context) Logger {
+	return logger.FromContext(ctx)
+}
+
+// Replace the function called by Fatal to exit the program. Default is os.Exit
+func SetExitFunc(f func(code int)) {
+	logger.SetExitFunc(f)
+}
+
+// Flush and close every stream attached to every logger and the global extra streams.
+// Call this before the program exits so no pending messages are lost.
+func Shutdown(ctx context.Context) error {
+	return logger.Shutdown(ctx)
+}
+
+// Structured field constructors. Pass these as the trailing arguments of any log method
+func String(key, value string) Field {
+	return message.String(key, value)
+}
+
+func Int(key string, value int) Field {
+	return message.Int(key, value)
+}
+
+func Int64(key string, value int64) Field {
+	return message.Int64(key, value)
+}
+
+func Float(key string, value float64) Field {
+	return message.Float(key, value)
+}
+
+func Bool(key string, value bool) Field {
+	return message.Bool(key, value)
+}
+
+func Duration(key string, value time.Duration) Field {
+	return message.Duration(key, value)
+}
+
+func Time(key string, value time.Time) Field {
+	return message.Time(key, value)
+}
+
+func Err(err error) Field {
+	return message.Err(err)
+}
+
+func NamedErr(key string, err error) Field {
+	return message.NamedErr(key, err)
+}
+
+func Any(key string, value interface{}) Field {
+	return message.Any(key, value)
+}
@@ -0,0 +1,82 @@
+package logger_test
+
+import (
+	"log/slog"
+	"runtime"
+	"strconv"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Get "caller_test.go:<line>" of the line above the caller
+func lineAbove() string {
+	_, _, line, _ := runtime.Caller(1)
+	return "caller_test.go:" + strconv.Itoa(line-1)
+}
+
+// Wrapper helper that needs CallerSkip
+func logThroughHelper(l logger.Logger, msg string) {
+	l.Info(msg)
+}
+
+var _ = Describe("Caller", func() {
+	ts := &TestStream{}
+	option := logger.LoggerOption{
+		Name:      "CallerTest",
+		Silent:    true,
+		AddCaller: true,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	}
+	l := logger.NewLogger(option)
+
+	It("Test caller of the log methods", func() {
+		l.Info("info")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+		Expect(ts.LastMessage.Caller.Function).To(ContainSubstring("logger_test"))
+
+		l.Infof("info %d", 1)
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+
+		l.Named("child").With().Warn("warn")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+
+		logger.SetExitFunc(func(int) {})
+		defer logger.SetExitFunc(nil)
+		l.Fatalf("fatal")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+	})
+
+	It("Test CallerSkip", func() {
+		o := option
+		o.CallerSkip = 1
+		logThroughHelper(logger.NewLogger(o), "helper")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+	})
+
+	It("Test caller through the adapters", func() {
+		logger.NewLogr(l).Info("logr")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+
+		logger.NewLogr(l).V(1).Error(nil, "logr error")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+
+		logger.NewStdLog(l, logger.Info).Printf("std log")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+
+		sl := slog.New(logger.NewSlogHandler(option))
+		sl.Info("slog")
+		Expect(ts.LastMessage.Caller.String()).To(Equal(lineAbove()))
+	})
+
+	It("Test no caller without AddCaller", func() {
+		o := option
+		o.AddCaller = false
+		logger.NewLogger(o).Info("info")
+		Expect(ts.LastMessage.Caller.Defined()).To(BeFalse())
+	})
+})
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+type (
+	// Extract the fields (e.g. request ID, user ID) to add to the logs from the context
+	ContextExtractor func(ctx context.Context) []message.Field
+
+	// Extract the IDs of the active trace span from the context. Empty strings if there is no span
+	TraceContextExtractor func(ctx context.Context) (traceId, spanId string)
+
+	// Key of the logger stored in the context
+	loggerContextKey struct{}
+)
+
+var (
+	// Extractors applied by Logger.Ctx
+	contextExtractors      []ContextExtractor
+	traceContextExtractor  TraceContextExtractor
+	contextExtractorsMutex sync.RWMutex
+
+	// Logger returned by FromContext when the context has no logger
+	defaultLogger     Logger
+	defaultLoggerOnce sync.Once
+)
+
+// Add an extractor applied by Logger.Ctx to all loggers
+func AddContextExtractor(e ContextExtractor) {
+	contextExtractorsMutex.Lock()
+	defer contextExtractorsMutex.Unlock()
+
+	contextExtractors = append(contextExtractors, e)
+}
+
+// Set the extractor of the trace and span IDs applied by Logger.Ctx (e.g. the ecl/otel package)
+func SetTraceContextExtractor(e TraceContextExtractor) {
+	contextExtractorsMutex.Lock()
+	defer contextExtractorsMutex.Unlock()
+
+	traceContextExtractor = e
+}
+
+// Get the trace and span IDs of the context. Empty if no trace context extractor is set
+func extractTraceContext(ctx context.Context) (string, string) {
+	contextExtractorsMutex.RLock()
+	e := traceContextExtractor
+	contextExtractorsMutex.RUnlock()
+
+	if e == nil {
+		return "", ""
+	}
+	return e(ctx)
+}
+
+// Run all extractors on the context
+func extractContextFields(ctx context.Context) []message.Field {
+	contextExtractorsMutex.RLock()
+	defer contextExtractorsMutex.RUnlock()
+
+	var fields []message.Field
+	for _, e := range contextExtractors {
+		fields = append(fields, e(ctx)...)
+	}
+
+	return fields
+}
+
+// Get a child logger with the fields and the trace span IDs extracted from the context
+func (l *LoggerImpl) Ctx(ctx context.Context) Logger {
+	child := l.with(extractContextFields(ctx))
+	child.traceId, child.spanId = extractTraceContext(ctx)
+	child.ctxFieldsBound = true
+	return child
+}
+
+// Get a child logger with the fields extracted from the context. The context is passed on to the slog handler
+func (l *slogLogger) Ctx(ctx context.Context) Logger {
+	// The ecl SlogHandler extracts the fields from the context by itself
+	if _, ok := l.logger.Handler().(*SlogHandler); ok {
+		child := *l
+		child.ctx = ctx
+		return &child
+	}
+
+	child := l.With(extractContextFields(ctx)...).(*slogLogger)
+	child.ctx = ctx
+	return child
+}
+
+// Get a copy of the context that carries the logger
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// Get the logger carried by the context. If there is none, a default logger is returned
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerContextKey{}).(Logger); ok {
+		return l
+	}
+
+	defaultLoggerOnce.Do(func() {
+		defaultLogger = NewLogger(LoggerOption{})
+	})
+
+	return defaultLogger
+}
@@ -0,0 +1,99 @@
+package logger_test
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type requestIdKey struct{}
+
+var _ = Describe("Context", func() {
+	ts := &TestStream{}
+	l := logger.NewLogger(logger.LoggerOption{
+		Name:   "ContextTest",
+		Silent: true,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	})
+
+	logger.AddContextExtractor(func(ctx context.Context) []message.Field {
+		if id, ok := ctx.Value(requestIdKey{}).(string); ok {
+			return []message.Field{message.String("request_id", id)}
+		}
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), requestIdKey{}, "abc")
+
+	It("Test Ctx", func() {
+		l.Ctx(ctx).Info("Hello, world!")
+
+		Expect(ts.LastMessage.Fields).To(HaveLen(1))
+		Expect(ts.LastMessage.Fields[0].Key).To(Equal("request_id"))
+		Expect(ts.LastMessage.Fields[0].ValueString()).To(Equal("abc"))
+
+		// Nothing to extract
+		l.Ctx(context.Background()).Info("Hello, world!")
+		Expect(ts.LastMessage.Fields).To(BeEmpty())
+	})
+
+	It("Test slog InfoContext", func() {
+		l.Slog().InfoContext(ctx, "Hello, world!")
+
+		Expect(ts.LastMessage.Fields).To(HaveLen(1))
+		Expect(ts.LastMessage.Fields[0].Key).To(Equal("request_id"))
+	})
+
+	It("Test Ctx with slog InfoContext", func() {
+		// The fields bound by Ctx are not extracted again
+		l.Ctx(ctx).Slog().InfoContext(ctx, "Hello, world!")
+
+		Expect(ts.LastMessage.Fields).To(HaveLen(1))
+		Expect(ts.LastMessage.Fields[0].Key).To(Equal("request_id"))
+		Expect(ts.LastMessage.Fields[0].ValueString()).To(Equal("abc"))
+	})
+
+	It("Test NewContext and FromContext", func() {
+		rl := l.Ctx(ctx)
+		Expect(logger.FromContext(logger.NewContext(ctx, rl))).To(BeIdenticalTo(rl))
+
+		// Default logger without a logger in the context
+		Expect(logger.FromContext(context.Background())).NotTo(BeNil())
+	})
+})
+
+var _ = Describe("Slog Context", func() {
+	It("Test FromSlog Ctx passes the context to the handler", func() {
+		var got context.Context
+		sl := slog.New(&contextCapturingHandler{ctx: &got})
+
+		logger.FromSlog(sl).Ctx(context.WithValue(context.Background(), requestIdKey{}, "abc")).Info("Hello, world!")
+		Expect(got.Value(requestIdKey{})).To(Equal("abc"))
+	})
+})
+
+// slog.Handler that captures the context of the last record
+type contextCapturingHandler struct {
+	slog.Handler
+	ctx *context.Context
+}
+
+func (h *contextCapturingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *contextCapturingHandler) Handle(ctx context.Context, r slog.Record) error {
+	*h.ctx = ctx
+	return nil
+}
+
+func (h *contextCapturingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h
+}
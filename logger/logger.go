@@ -1,9 +1,15 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jhseong7/ecl/message"
@@ -30,19 +36,31 @@ type (
 
 		// Local App name. If set, this name will be added to all log messages as a prefix.
 		AppName string
+
+		// If true, the file, line and function of the caller are added to all log messages
+		AddCaller bool
+
+		// Number of extra frames to skip to find the caller. Set this when the logger is called through wrapper helpers
+		CallerSkip int
+
+		// If true, the stack trace of the caller is added to the log messages at or above the StacktraceLevel
+		AddStacktrace bool
+
+		// Min level to capture the stack trace. Default is Error (Fatal and Panic are always above)
+		StacktraceLevel LogLevel
 	}
 
 	Logger interface {
-		Log(msg string)
-		Trace(msg string)
-		Debug(msg string)
-		Info(msg string)
-		Warn(msg string)
-		Error(msg string)
-		Fatal(msg string)
-		Panic(msg string)
-
-		// format functions
+		Log(msg string, fields ...message.Field)
+		Trace(msg string, fields ...message.Field)
+		Debug(msg string, fields ...message.Field)
+		Info(msg string, fields ...message.Field)
+		Warn(msg string, fields ...message.Field)
+		Error(msg string, fields ...message.Field)
+		Fatal(msg string, fields ...message.Field)
+		Panic(msg string, fields ...message.Field)
+
+		// format functions. Any message.Field given in args is attached as a field instead of being formatted
 		Logf(format string, args ...interface{})
 		Tracef(format string, args ...interface{})
 		Debugf(format string, args ...interface{})
@@ -51,6 +69,16 @@ type (
 		Errorf(format string, args ...interface{})
 		Fatalf(format string, args ...interface{})
 		Panicf(format string, args ...interface{})
+
+		// Child loggers
+		With(fields ...message.Field) Logger
+		Named(sub string) Logger
+
+		// Get a log/slog logger that writes through this logger
+		Slog() *slog.Logger
+
+		// Get a child logger with the fields extracted from the context by the context extractors
+		Ctx(ctx context.Context) Logger
 	}
 
 	LoggerImpl struct {
@@ -59,11 +87,33 @@ type (
 		name     string
 		loglevel LogLevel
 		appName  string
+
+		// Fields bound by With. These are prepended to the fields of every message
+		fields []message.Field
+
+		addCaller  bool
+		callerSkip int
+
+		addStacktrace   bool
+		stacktraceLevel LogLevel
+
+		// IDs of the trace span bound by Ctx
+		traceId string
+		spanId  string
+
+		// If true, the fields of the context extractors were bound by Ctx, so the slog handler does not extract them again
+		ctxFieldsBound bool
 	}
 
 	LogLevel int
 )
 
+// Frames between runtime.Callers and the caller of the public log method (runtime.Callers, getCallSite, writeToStream, Info)
+const callerSkipOffset = 4
+
+// Max number of frames in a stack trace
+const maxStacktraceDepth = 64
+
 const (
 	All LogLevel = iota
 	Trace
@@ -73,6 +123,12 @@ const (
 	Error
 
 	// No options for Fatal and Panic (always print)
+
+	// Level of Fatal and Panic. Only used to compare with the StacktraceLevel
+	fatalLevel
+
+	// Level of Log through slog. Only used to map the slog level back, as Log is never filtered
+	logLevel
 )
 
 var (
@@ -86,6 +142,14 @@ var (
 	globalLogStyle style.LogStyle = style.DefaultStyle
 
 	globalLoglevel LogLevel
+
+	// Called by Fatal after the streams are closed
+	exitFunc = os.Exit
+
+	// All streams attached to the loggers and the global extra streams. Flushed and closed by Shutdown
+	streamRegistry      []stream.ILogStream
+	streamRegistrySet   = map[stream.ILogStream]struct{}{}
+	streamRegistryMutex sync.Mutex
 )
 
 // Init function when loading the package
@@ -98,6 +162,10 @@ func init() {
 }
 
 func NewLogger(o LoggerOption) Logger {
+	return newLoggerImpl(o)
+}
+
+func newLoggerImpl(o LoggerOption) *LoggerImpl {
 	var ss []stream.ILogStream
 
 	if !o.Silent {
@@ -119,6 +187,8 @@ func NewLogger(o LoggerOption) Logger {
 	// Append extra streams
 	ss = append(ss, o.ExtraStreams...)
 
+	registerStreams(ss)
+
 	// Set loglevel
 	var loglevel LogLevel
 	if o.LogLevel != 0 {
@@ -127,11 +197,22 @@ func NewLogger(o LoggerOption) Logger {
 		loglevel = globalLoglevel
 	}
 
+	// Set the stack trace level
+	stacktraceLevel := o.StacktraceLevel
+	if stacktraceLevel == All {
+		stacktraceLevel = Error
+	}
+
 	return &LoggerImpl{
-		name:     o.Name,
-		Streams:  ss,
-		loglevel: loglevel,
-		appName:  o.AppName,
+		name:       o.Name,
+		Streams:    ss,
+		loglevel:   loglevel,
+		appName:    o.AppName,
+		addCaller:  o.AddCaller,
+		callerSkip: o.CallerSkip,
+
+		addStacktrace:   o.AddStacktrace,
+		stacktraceLevel: stacktraceLevel,
 	}
 }
 
@@ -140,6 +221,15 @@ func SetLogLevel(level LogLevel) {
 	globalLoglevel = level
 }
 
+// Replace the function called by Fatal to exit the program. Default is os.Exit.
+// Useful to intercept Fatal in tests
+func SetExitFunc(f func(code int)) {
+	if f == nil {
+		f = os.Exit
+	}
+	exitFunc = f
+}
+
 // Set the global prefix for the logger. If set, this name will be added to all log messages as a prefix.
 func SetAppName(appName string) {
 	globalAppName = appName
@@ -153,12 +243,177 @@ func SetLogStyle(style style.LogStyle) {
 // Add the streams to the global extra streams. This will be added to all loggers.
 func AddGlobalExtraStream(streams []stream.ILogStream) {
 	globalExtraStreams = append(globalExtraStreams, streams...)
+	registerStreams(streams)
 }
 
-func (l *LoggerImpl) writeToStream(color, logLevel, msg string) {
+// Add the streams to the registry used by Shutdown. A stream shared by multiple loggers is registered once.
+// Only the streams with something to flush or close are kept, so creating loggers does not grow the registry
+func registerStreams(streams []stream.ILogStream) {
+	streamRegistryMutex.Lock()
+	defer streamRegistryMutex.Unlock()
+
+	for _, s := range streams {
+		_, flushable := s.(stream.IFlushableLogStream)
+		_, closable := s.(stream.IClosableLogStream)
+		if !flushable && !closable {
+			continue
+		}
+
+		if reflect.ValueOf(s).Comparable() {
+			if _, ok := streamRegistrySet[s]; ok {
+				continue
+			}
+			streamRegistrySet[s] = struct{}{}
+		} else if isStreamRegistered(s) {
+			// Streams that cannot be used as a map key are compared one by one
+			continue
+		}
+
+		streamRegistry = append(streamRegistry, s)
+	}
+}
+
+// Check if an equal stream is in the registry. Used for the streams that cannot be used as a map key
+func isStreamRegistered(s stream.ILogStream) bool {
+	for _, r := range streamRegistry {
+		if reflect.TypeOf(r) == reflect.TypeOf(s) && reflect.DeepEqual(r, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush and close the streams. Returns the first error
+func closeStreams(streams []stream.ILogStream) error {
+	var firstErr error
+
+	for _, s := range streams {
+		if err := stream.Flush(s); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err := stream.Close(s); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Flush and close every stream attached to every logger and the global extra streams.
+// Call this before the program exits so no pending messages are lost.
+// If the context is done before all streams are closed, the context's error is returned
+func Shutdown(ctx context.Context) error {
+	streamRegistryMutex.Lock()
+	streams := streamRegistry
+	streamRegistry = nil
+	streamRegistrySet = map[stream.ILogStream]struct{}{}
+	streamRegistryMutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- closeStreams(streams)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NOTE: Must be called directly from the public log methods so the caller frame depth is the same for all methods
+func (l *LoggerImpl) writeToStream(color, logLevel, msg string, fields []message.Field) {
 	// Get the current time here so that all streams have the same time
-	ct := time.Now()
+	caller, stacktrace := l.getCallSite(logLevel)
+	l.writeMessage(time.Now(), caller, stacktrace, color, logLevel, msg, fields)
+}
+
+// Get the caller of the public log method, and the stack trace if the level is at or above the StacktraceLevel.
+// Empty if AddCaller and AddStacktrace are not set
+func (l *LoggerImpl) getCallSite(logLevel string) (message.Caller, string) {
+	captureStack := l.addStacktrace && getLevelOfName(logLevel) >= l.stacktraceLevel
+	if !l.addCaller && !captureStack {
+		return message.Caller{}, ""
+	}
 
+	depth := 1
+	if captureStack {
+		depth = maxStacktraceDepth
+	}
+
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(callerSkipOffset+l.callerSkip, pcs)
+	if n == 0 {
+		return message.Caller{}, ""
+	}
+
+	var caller message.Caller
+	if l.addCaller {
+		caller = message.CallerFromPC(pcs[0])
+	}
+
+	var stacktrace string
+	if captureStack {
+		stacktrace = formatStacktrace(pcs[:n])
+	}
+
+	return caller, stacktrace
+}
+
+// Format the frames like runtime/debug.Stack. "function\n\tfile:line" per frame
+func formatStacktrace(pcs []uintptr) string {
+	var sb strings.Builder
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(frame.Function)
+			sb.WriteString("\n\t")
+			sb.WriteString(frame.File)
+			sb.WriteString(":")
+			sb.WriteString(strconv.Itoa(frame.Line))
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+// Get the log level of the level name. FATAL and PANIC are above Error, LOG is All
+func getLevelOfName(name string) LogLevel {
+	switch name {
+	case "TRACE":
+		return Trace
+	case "DEBUG":
+		return Debug
+	case "INFO":
+		return Info
+	case "WARN":
+		return Warn
+	case "ERROR":
+		return Error
+	case "FATAL", "PANIC":
+		return fatalLevel
+	default:
+		return All
+	}
+}
+
+// Write the message with the given time and call site. Used directly by adapters that carry their own record time (e.g. slog)
+func (l *LoggerImpl) writeMessage(ct time.Time, caller message.Caller, stacktrace, color, logLevel, msg string, fields []message.Field) {
+	l.writeMessageWithTrace(ct, caller, stacktrace, l.traceId, l.spanId, color, logLevel, msg, fields)
+}
+
+// Write the message with the given trace span IDs. Used by the slog handler which gets the context per record
+func (l *LoggerImpl) writeMessageWithTrace(ct time.Time, caller message.Caller, stacktrace, traceId, spanId, color, logLevel, msg string, fields []message.Field) {
 	// If the app name is not given --> get from the global app name
 	// Always check this as there are cases where users want to use the global app name
 	// for loggers initialized before the global app name is set
@@ -169,6 +424,11 @@ func (l *LoggerImpl) writeToStream(color, logLevel, msg string) {
 		appName = l.appName
 	}
 
+	// Prepend the bound fields
+	if len(l.fields) > 0 {
+		fields = append(append(make([]message.Field, 0, len(l.fields)+len(fields)), l.fields...), fields...)
+	}
+
 	// For all streams
 	for _, stream := range l.Streams {
 		// Write the log message
@@ -179,18 +439,98 @@ func (l *LoggerImpl) writeToStream(color, logLevel, msg string) {
 			Color:   color,
 			Level:   logLevel,
 			Msg:     msg,
+			Fields:  fields,
+			Caller:  caller,
+			TraceID: traceId,
+			SpanID:  spanId,
+
+			Stacktrace: stacktrace,
 		})
 	}
+}
+
+// Get a child logger that adds the given fields to every message.
+// The child shares the streams, log level and app name of the parent
+func (l *LoggerImpl) With(fields ...message.Field) Logger {
+	return l.with(fields)
+}
 
+func (l *LoggerImpl) with(fields []message.Field) *LoggerImpl {
+	child := *l
+	child.fields = append(append(make([]message.Field, 0, len(l.fields)+len(fields)), l.fields...), fields...)
+	return &child
 }
 
+// Get a child logger named "<parent>.<sub>". The child shares the streams, log level, app name and bound fields of the parent
+func (l *LoggerImpl) Named(sub string) Logger {
+	child := *l
+	switch {
+	case l.name == "":
+		child.name = sub
+	case sub != "":
+		child.name = l.name + "." + sub
+	}
+	return &child
+}
+
+// Flush the streams of the logger. Used before panicking as the program may still recover
+func (l *LoggerImpl) flushStreams() {
+	for _, s := range l.Streams {
+		stream.Flush(s)
+	}
+}
+
+// Flush and close every registered stream as Shutdown does, then exit the program with code 1.
+// The streams of the other loggers are included as their queued messages would be lost on exit
+func (l *LoggerImpl) exit() {
+	// Register the streams of the logger again in case an earlier Shutdown removed them
+	registerStreams(l.Streams)
+	Shutdown(context.Background())
+	exitFunc(1)
+}
+
+// Split the message.Field values out of the format arguments
+func splitFieldArgs(args []interface{}) ([]interface{}, []message.Field) {
+	var fields []message.Field
+	var rest []interface{}
+
+	for _, arg := range args {
+		if f, ok := arg.(message.Field); ok {
+			fields = append(fields, f)
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+
+	// No fields given --> keep the original args as is
+	if fields == nil {
+		return args, nil
+	}
+
+	return rest, fields
+}
+
+// NOTE: Does not call writeToStream so the caller frame depth is the same as the non-formatted methods
 func (l *LoggerImpl) logWithColorf(color, logLevel, format string, args ...interface{}) {
-	l.writeToStream(color, logLevel, fmt.Sprintf(format, args...))
+	args, fields := splitFieldArgs(args)
+	caller, stacktrace := l.getCallSite(logLevel)
+	l.writeMessage(time.Now(), caller, stacktrace, color, logLevel, fmt.Sprintf(format, args...), fields)
+}
+
+// Get a child logger that skips n more frames to find the caller. Other Logger implementations are returned as is
+func addCallerSkip(l Logger, n int) Logger {
+	if li, ok := l.(*LoggerImpl); ok {
+		child := *li
+		child.callerSkip += n
+		return &child
+	}
+
+	return l
 }
 
-func (l *LoggerImpl) Log(msg string) {
+func (l *LoggerImpl) Log(msg string, fields ...message.Field) {
 	// Green
-	l.writeToStream(style.Green, "LOG", msg)
+	l.writeToStream(style.Green, "LOG", msg, fields)
 }
 
 // Formatted Log log. Use this like fmt.Printf
@@ -199,12 +539,12 @@ func (l *LoggerImpl) Logf(format string, args ...interface{}) {
 	l.logWithColorf(style.Green, "LOG", format, args...)
 }
 
-func (l *LoggerImpl) Trace(msg string) {
+func (l *LoggerImpl) Trace(msg string, fields ...message.Field) {
 	if l.loglevel > Trace {
 		return
 	}
 
-	l.writeToStream(style.Purple, "TRACE", msg)
+	l.writeToStream(style.Purple, "TRACE", msg, fields)
 }
 
 // Formatted Trace log. Use this like fmt.Printf
@@ -216,13 +556,13 @@ func (l *LoggerImpl) Tracef(format string, args ...interface{}) {
 	l.logWithColorf(style.Purple, "TRACE", format, args...)
 }
 
-func (l *LoggerImpl) Debug(msg string) {
+func (l *LoggerImpl) Debug(msg string, fields ...message.Field) {
 	if l.loglevel > Debug {
 		return
 	}
 
 	// Blue
-	l.writeToStream(style.Blue, "DEBUG", msg)
+	l.writeToStream(style.Blue, "DEBUG", msg, fields)
 }
 
 // Formatted Debug log. Use this like fmt.Printf
@@ -235,13 +575,13 @@ func (l *LoggerImpl) Debugf(format string, args ...interface{}) {
 	l.logWithColorf(style.Blue, "DEBUG", format, args...)
 }
 
-func (l *LoggerImpl) Info(msg string) {
+func (l *LoggerImpl) Info(msg string, fields ...message.Field) {
 	if l.loglevel > Info {
 		return
 	}
 
 	// Green
-	l.writeToStream(style.Cyan, "INFO", msg)
+	l.writeToStream(style.Cyan, "INFO", msg, fields)
 }
 
 // Formatted Log log. Use this like fmt.Printf
@@ -255,13 +595,13 @@ func (l *LoggerImpl) Infof(format string, args ...interface{}) {
 }
 
 // Warn Log
-func (l *LoggerImpl) Warn(msg string) {
+func (l *LoggerImpl) Warn(msg string, fields ...message.Field) {
 	if l.loglevel > Warn {
 		return
 	}
 
 	// Yellow
-	l.writeToStream(style.Yellow, "WARN", msg)
+	l.writeToStream(style.Yellow, "WARN", msg, fields)
 }
 
 // Formatted Warn log. Use this like fmt.Printf
@@ -274,13 +614,13 @@ func (l *LoggerImpl) Warnf(format string, args ...interface{}) {
 	l.logWithColorf(style.Yellow, "WARN", format, args...)
 }
 
-func (l *LoggerImpl) Error(msg string) {
+func (l *LoggerImpl) Error(msg string, fields ...message.Field) {
 	if l.loglevel > Error {
 		return
 	}
 
 	// Red
-	l.writeToStream(style.Red, "ERROR", msg)
+	l.writeToStream(style.Red, "ERROR", msg, fields)
 }
 
 // Formatted Error log. Use this like fmt.Printf
@@ -293,28 +633,34 @@ func (l *LoggerImpl) Errorf(format string, args ...interface{}) {
 	l.logWithColorf(style.Red, "ERROR", format, args...)
 }
 
-func (l *LoggerImpl) Fatal(msg string) {
+func (l *LoggerImpl) Fatal(msg string, fields ...message.Field) {
 	// Red + Fatal + Exit(1)
-	l.writeToStream(style.Red, "FATAL", msg)
-	log.Fatal(msg)
+	l.writeToStream(style.Red, "FATAL", msg, fields)
+	l.exit()
 }
 
 // Formatted Fatal log. Use this like fmt.Printf
 func (l *LoggerImpl) Fatalf(format string, args ...interface{}) {
 	// Red + Fatal + Exit(1)
-	l.logWithColorf(style.Red, "FATAL", format, args...)
-	log.Fatalf(format, args...)
+	args, fields := splitFieldArgs(args)
+	msg := fmt.Sprintf(format, args...)
+	l.writeToStream(style.Red, "FATAL", msg, fields)
+	l.exit()
 }
 
-func (l *LoggerImpl) Panic(msg string) {
+func (l *LoggerImpl) Panic(msg string, fields ...message.Field) {
 	// Red + Panic + Exit(1)
-	l.writeToStream(style.Red, "PANIC", msg)
+	l.writeToStream(style.Red, "PANIC", msg, fields)
+	l.flushStreams()
 	panic(msg)
 }
 
 // Formatted Panic log. Use this like fmt.Printf
 func (l *LoggerImpl) Panicf(format string, args ...interface{}) {
 	// Red + Panic + Exit(1)
-	l.logWithColorf(style.Red, "PANIC", format, args...)
-	panic(fmt.Sprintf(format, args...))
+	args, fields := splitFieldArgs(args)
+	msg := fmt.Sprintf(format, args...)
+	l.writeToStream(style.Red, "PANIC", msg, fields)
+	l.flushStreams()
+	panic(msg)
 }
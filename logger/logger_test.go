@@ -1,11 +1,14 @@
 package logger_test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/jhseong7/ecl/logger"
 	"github.com/jhseong7/ecl/message"
 	"github.com/jhseong7/ecl/stream"
+	"github.com/jhseong7/ecl/style"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -19,6 +22,33 @@ func (s *TestStream) Write(msg message.LogMessage) {
 	s.LastMessage = msg
 }
 
+type ClosableTestStream struct {
+	TestStream
+	Calls []string
+}
+
+func (s *ClosableTestStream) Flush() error {
+	s.Calls = append(s.Calls, "flush")
+	return nil
+}
+
+func (s *ClosableTestStream) Close() error {
+	s.Calls = append(s.Calls, "close")
+	return nil
+}
+
+// Closable stream of a type that cannot be used as a map key
+type ValueClosableTestStream struct {
+	stream.ILogStream
+	Tags  []string
+	Calls *[]string
+}
+
+func (s ValueClosableTestStream) Close() error {
+	*s.Calls = append(*s.Calls, "close")
+	return nil
+}
+
 var _ = Describe("Coloured Logger", func() {
 	// Setup the logger
 	ts := &TestStream{}
@@ -114,6 +144,189 @@ var _ = Describe("Coloured Logger", func() {
 		// Check the level
 		Expect(m.Level).To(Equal("ERROR"))
 	})
+
+	It("Test Fields", func() {
+		const msg = "Hello, world!"
+		l.Info(msg, message.String("user", "john"), message.Int("count", 3))
+
+		// Check the contents
+		m := ts.LastMessage
+
+		// Check the message
+		Expect(m.Msg).To(Equal(msg))
+
+		// Check the fields
+		Expect(m.Fields).To(HaveLen(2))
+		Expect(m.Fields[0].Key).To(Equal("user"))
+		Expect(m.Fields[0].ValueString()).To(Equal("john"))
+		Expect(m.Fields[1].Key).To(Equal("count"))
+		Expect(m.Fields[1].ValueString()).To(Equal("3"))
+	})
+
+	It("Test Fields in formatted log", func() {
+		l.Infof("Hello, %s!", "world", message.Bool("ok", true))
+
+		// Check the contents
+		m := ts.LastMessage
+
+		// Field args must not be formatted into the message
+		Expect(m.Msg).To(Equal("Hello, world!"))
+
+		// Check the fields
+		Expect(m.Fields).To(HaveLen(1))
+		Expect(m.Fields[0].Key).To(Equal("ok"))
+		Expect(m.Fields[0].ValueString()).To(Equal("true"))
+	})
+
+	It("Test hand-built fields", func() {
+		// Values not matching the type must not panic
+		fields := []message.Field{
+			{Key: "count", Type: message.IntType, Value: 3},
+			{Key: "ok", Type: message.BoolType, Value: "yes"},
+			{Key: "err", Type: message.ErrorType, Value: "failed"},
+		}
+		Expect(func() { l.Info("hand-built", fields...) }).NotTo(Panic())
+
+		m := ts.LastMessage
+		Expect(m.Fields[0].ValueString()).To(Equal("3"))
+		Expect(m.Fields[1].ValueString()).To(Equal("yes"))
+		Expect(m.Fields[2].ValueString()).To(Equal("failed"))
+		Expect(json.Valid([]byte(style.GetMessageOfStyle(m, style.JsonStyle)))).To(BeTrue())
+		Expect(func() { style.GetMessageOfStyle(m, style.DefaultStyle) }).NotTo(Panic())
+	})
+})
+
+var _ = Describe("Child Logger", func() {
+	ts := &TestStream{}
+	l := logger.NewLogger(logger.LoggerOption{
+		Name:    "OrderService",
+		AppName: "Shop",
+		Silent:  true,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	})
+
+	It("Test With", func() {
+		child := l.With(message.String("request_id", "abc"))
+		child.Info("Hello, world!", message.Int("count", 1))
+
+		// Check the contents
+		m := ts.LastMessage
+
+		// Bound fields come first
+		Expect(m.Fields).To(HaveLen(2))
+		Expect(m.Fields[0].Key).To(Equal("request_id"))
+		Expect(m.Fields[1].Key).To(Equal("count"))
+
+		// The parent must not be affected
+		l.Info("Hello, world!")
+		Expect(ts.LastMessage.Fields).To(BeEmpty())
+	})
+
+	It("Test Named", func() {
+		child := l.Named("checkout").With(message.String("tenant", "t1"))
+		child.Info("Hello, world!")
+
+		// Check the contents
+		m := ts.LastMessage
+
+		// Check the name and shared app name
+		Expect(m.Name).To(Equal("OrderService.checkout"))
+		Expect(m.AppName).To(Equal("Shop"))
+		Expect(m.Fields).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Shutdown", func() {
+	It("Test Shutdown flushes and closes shared streams once", func() {
+		ts := &ClosableTestStream{}
+		option := logger.LoggerOption{
+			Silent: true,
+			ExtraStreams: []stream.ILogStream{
+				ts,
+			},
+		}
+		logger.NewLogger(option)
+		logger.NewLogger(option)
+
+		Expect(logger.Shutdown(context.Background())).To(Succeed())
+		Expect(ts.Calls).To(Equal([]string{"flush", "close"}))
+	})
+
+	It("Test Shutdown closes non-comparable streams once", func() {
+		var calls []string
+		vs := ValueClosableTestStream{Tags: []string{"a"}, Calls: &calls}
+		option := logger.LoggerOption{
+			Silent: true,
+			ExtraStreams: []stream.ILogStream{
+				vs,
+			},
+		}
+		logger.NewLogger(option)
+		logger.NewLogger(option)
+
+		Expect(logger.Shutdown(context.Background())).To(Succeed())
+		Expect(calls).To(Equal([]string{"close"}))
+	})
+})
+
+var _ = Describe("Fatal and Panic", func() {
+	It("Test Fatal closes the streams before exiting", func() {
+		ts := &ClosableTestStream{}
+		l := logger.NewLogger(logger.LoggerOption{
+			Silent: true,
+			ExtraStreams: []stream.ILogStream{
+				ts,
+			},
+		})
+
+		exitCode := -1
+		logger.SetExitFunc(func(code int) {
+			exitCode = code
+		})
+		defer logger.SetExitFunc(nil)
+
+		l.Fatalf("fatal %d", 1)
+
+		Expect(ts.LastMessage.Msg).To(Equal("fatal 1"))
+		Expect(ts.LastMessage.Level).To(Equal("FATAL"))
+		Expect(ts.Calls).To(Equal([]string{"flush", "close"}))
+		Expect(exitCode).To(Equal(1))
+	})
+
+	It("Test Fatal closes the streams of the other loggers", func() {
+		other := &ClosableTestStream{}
+		logger.NewLogger(logger.LoggerOption{
+			Silent: true,
+			ExtraStreams: []stream.ILogStream{
+				other,
+			},
+		})
+
+		l := logger.NewLogger(logger.LoggerOption{Silent: true})
+
+		logger.SetExitFunc(func(int) {})
+		defer logger.SetExitFunc(nil)
+
+		l.Fatal("fatal")
+
+		Expect(other.Calls).To(Equal([]string{"flush", "close"}))
+	})
+
+	It("Test Panic flushes the streams before panicking", func() {
+		ts := &ClosableTestStream{}
+		l := logger.NewLogger(logger.LoggerOption{
+			Silent: true,
+			ExtraStreams: []stream.ILogStream{
+				ts,
+			},
+		})
+
+		Expect(func() { l.Panic("panic") }).To(PanicWith("panic"))
+		Expect(ts.LastMessage.Level).To(Equal("PANIC"))
+		Expect(ts.Calls).To(Equal([]string{"flush"}))
+	})
 })
 
 func TestLogger(t *testing.T) {
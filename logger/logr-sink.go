@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/jhseong7/ecl/message"
+)
+
+type (
+	// logr.LogSink that writes through an ecl Logger
+	LogrSink struct {
+		logger Logger
+	}
+)
+
+// Frames of the sink between logr and the Logger (Info/Error, logAtLevel)
+const logrSinkCallerSkip = 2
+
+// Map the logr V-level onto the ecl log level. V(0) is Info, V(1) is Debug and V(2) or above is Trace
+func logrLevelToLogLevel(level int) LogLevel {
+	switch {
+	case level <= 0:
+		return Info
+	case level == 1:
+		return Debug
+	default:
+		return Trace
+	}
+}
+
+// Convert the value to a field, keeping the value type where possible
+func anyToField(key string, value interface{}) message.Field {
+	switch v := value.(type) {
+	case string:
+		return message.String(key, v)
+	case int:
+		return message.Int(key, v)
+	case int64:
+		return message.Int64(key, v)
+	case int32:
+		return message.Int64(key, int64(v))
+	case float64:
+		return message.Float(key, v)
+	case float32:
+		return message.Float(key, float64(v))
+	case bool:
+		return message.Bool(key, v)
+	case time.Duration:
+		return message.Duration(key, v)
+	case time.Time:
+		return message.Time(key, v)
+	case error:
+		return message.NamedErr(key, v)
+	default:
+		return message.Any(key, v)
+	}
+}
+
+// Convert the logr key/value pairs to fields. A dangling key gets the "!BADKEY" key as in slog
+func keysAndValuesToFields(keysAndValues []interface{}) []message.Field {
+	fields := make([]message.Field, 0, (len(keysAndValues)+1)/2)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 >= len(keysAndValues) {
+			fields = append(fields, anyToField("!BADKEY", keysAndValues[i]))
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		fields = append(fields, anyToField(key, keysAndValues[i+1]))
+	}
+
+	return fields
+}
+
+// Skip the logr frames (and the frames of the sink itself) to find the caller
+func (s *LogrSink) Init(info logr.RuntimeInfo) {
+	s.logger = addCallerSkip(s.logger, info.CallDepth+logrSinkCallerSkip)
+}
+
+// Implements logr.CallDepthLogSink for logr.Logger.WithCallDepth
+func (s *LogrSink) WithCallDepth(depth int) logr.LogSink {
+	return &LogrSink{
+		logger: addCallerSkip(s.logger, depth),
+	}
+}
+
+func (s *LogrSink) Enabled(level int) bool {
+	// Other Logger implementations filter the levels by themselves
+	if l, ok := s.logger.(*LoggerImpl); ok {
+		return l.loglevel <= logrLevelToLogLevel(level)
+	}
+
+	return true
+}
+
+func (s *LogrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	logAtLevel(s.logger, logrLevelToLogLevel(level), msg, keysAndValuesToFields(keysAndValues))
+}
+
+func (s *LogrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fields := append([]message.Field{message.Err(err)}, keysAndValuesToFields(keysAndValues)...)
+	logAtLevel(s.logger, Error, msg, fields)
+}
+
+func (s *LogrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &LogrSink{
+		logger: s.logger.With(keysAndValuesToFields(keysAndValues)...),
+	}
+}
+
+// The name is appended to the logger name with a dot (e.g. OrderService.controller)
+func (s *LogrSink) WithName(name string) logr.LogSink {
+	return &LogrSink{
+		logger: s.logger.Named(name),
+	}
+}
+
+// Create a logr.LogSink that writes through the logger
+func NewLogrSink(l Logger) *LogrSink {
+	return &LogrSink{
+		logger: l,
+	}
+}
+
+// Create a logr.Logger that writes through the logger
+func NewLogr(l Logger) logr.Logger {
+	return logr.New(NewLogrSink(l))
+}
@@ -0,0 +1,55 @@
+package logger_test
+
+import (
+	"errors"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logr Sink", func() {
+	ts := &TestStream{}
+	l := logger.NewLogger(logger.LoggerOption{
+		Name:     "Controller",
+		Silent:   true,
+		LogLevel: logger.Debug,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	})
+	lr := logger.NewLogr(l)
+
+	It("Test V-levels", func() {
+		lr.Info("info")
+		Expect(ts.LastMessage.Level).To(Equal("INFO"))
+
+		lr.V(1).Info("debug")
+		Expect(ts.LastMessage.Level).To(Equal("DEBUG"))
+
+		// Trace is below the logger's level
+		lr.V(2).Info("trace")
+		Expect(ts.LastMessage.Msg).To(Equal("debug"))
+	})
+
+	It("Test Error, WithName and WithValues", func() {
+		lr.WithName("reconciler").WithValues("namespace", "default").Error(errors.New("boom"), "failed", "retry", 3)
+
+		m := ts.LastMessage
+		Expect(m.Level).To(Equal("ERROR"))
+		Expect(m.Msg).To(Equal("failed"))
+		Expect(m.Name).To(Equal("Controller.reconciler"))
+
+		fields := map[string]string{}
+		for _, f := range m.Fields {
+			fields[f.Key] = f.ValueString()
+		}
+
+		Expect(fields).To(Equal(map[string]string{
+			"namespace": "default",
+			"error":     "boom",
+			"retry":     "3",
+		}))
+	})
+})
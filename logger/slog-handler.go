@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/style"
+)
+
+type (
+	// slog.Handler that writes the records to the ecl streams with the ecl styles
+	SlogHandler struct {
+		logger *LoggerImpl
+
+		// Key prefix of the attrs added after WithGroup. e.g. "request."
+		groupPrefix string
+	}
+)
+
+// Map the slog level onto the ecl log level. Levels below slog.LevelDebug are mapped to Trace
+func slogLevelToLogLevel(level slog.Level) LogLevel {
+	switch {
+	case level >= slogLevelLog:
+		return logLevel
+	case level < slog.LevelDebug:
+		return Trace
+	case level < slog.LevelInfo:
+		return Debug
+	case level < slog.LevelWarn:
+		return Info
+	case level < slog.LevelError:
+		return Warn
+	default:
+		return Error
+	}
+}
+
+// Get the colour and the name of the log level
+func getLevelStyle(level LogLevel) (string, string) {
+	switch level {
+	case Trace:
+		return style.Purple, "TRACE"
+	case Debug:
+		return style.Blue, "DEBUG"
+	case Info:
+		return style.Cyan, "INFO"
+	case Warn:
+		return style.Yellow, "WARN"
+	case Error:
+		return style.Red, "ERROR"
+	default:
+		return style.Green, "LOG"
+	}
+}
+
+// Convert the slog attr to fields. Groups are flattened with dotted keys (e.g. request.id)
+func appendAttrFields(fields []message.Field, prefix string, a slog.Attr) []message.Field {
+	a.Value = a.Value.Resolve()
+
+	// Empty attrs are ignored as in the slog handler rules
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	key := prefix + a.Key
+	v := a.Value
+
+	switch v.Kind() {
+	case slog.KindGroup:
+		// Groups without a key are inlined
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = key + "."
+		}
+
+		for _, ga := range v.Group() {
+			fields = appendAttrFields(fields, groupPrefix, ga)
+		}
+		return fields
+	case slog.KindString:
+		return append(fields, message.String(key, v.String()))
+	case slog.KindInt64:
+		return append(fields, message.Int64(key, v.Int64()))
+	case slog.KindFloat64:
+		return append(fields, message.Float(key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, message.Bool(key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, message.Duration(key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, message.Time(key, v.Time()))
+	default:
+		if err, ok := v.Any().(error); ok {
+			return append(fields, message.NamedErr(key, err))
+		}
+		return append(fields, message.Any(key, v.Any()))
+	}
+}
+
+// Get the stack trace starting from the frame of the program counter, so the slog frames are trimmed out
+func getStacktraceFromPC(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+
+	pcs := make([]uintptr, maxStacktraceDepth)
+	n := runtime.Callers(1, pcs)
+
+	for i := 0; i < n; i++ {
+		if pcs[i] == pc {
+			return formatStacktrace(pcs[i:n])
+		}
+	}
+
+	return ""
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.loglevel <= slogLevelToLogLevel(level)
+}
+
+// The fields extracted from the context by the context extractors are added (e.g. slog.InfoContext),
+// unless the logger already has them bound by Ctx
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	color, levelName := getLevelStyle(slogLevelToLogLevel(r.Level))
+
+	var fields []message.Field
+	traceId, spanId := h.logger.traceId, h.logger.spanId
+	if ctx != nil {
+		if !h.logger.ctxFieldsBound {
+			fields = extractContextFields(ctx)
+		}
+
+		// The span of the record's context takes precedence over the one bound by Ctx
+		if ti, si := extractTraceContext(ctx); ti != "" {
+			traceId, spanId = ti, si
+		}
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttrFields(fields, h.groupPrefix, a)
+		return true
+	})
+
+	ct := r.Time
+	if ct.IsZero() {
+		ct = time.Now()
+	}
+
+	// The record carries the caller's program counter
+	var caller message.Caller
+	if h.logger.addCaller {
+		caller = message.CallerFromPC(r.PC)
+	}
+
+	var stacktrace string
+	if h.logger.addStacktrace && getLevelOfName(levelName) >= h.logger.stacktraceLevel {
+		stacktrace = getStacktraceFromPC(r.PC)
+	}
+
+	h.logger.writeMessageWithTrace(ct, caller, stacktrace, traceId, spanId, color, levelName, r.Message, fields)
+
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []message.Field
+	for _, a := range attrs {
+		fields = appendAttrFields(fields, h.groupPrefix, a)
+	}
+
+	return &SlogHandler{
+		logger:      h.logger.with(fields),
+		groupPrefix: h.groupPrefix,
+	}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogHandler{
+		logger:      h.logger,
+		groupPrefix: h.groupPrefix + name + ".",
+	}
+}
+
+// Create a slog.Handler with the same streams, style, level and app name options as NewLogger
+func NewSlogHandler(o LoggerOption) *SlogHandler {
+	return &SlogHandler{
+		logger: newLoggerImpl(o),
+	}
+}
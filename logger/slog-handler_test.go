@@ -0,0 +1,71 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Slog Handler", func() {
+	ts := &TestStream{}
+	h := logger.NewSlogHandler(logger.LoggerOption{
+		Name:     "SlogService",
+		Silent:   true,
+		LogLevel: logger.Debug,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	})
+	sl := slog.New(h)
+
+	It("Test level mapping", func() {
+		sl.Info("Hello, world!")
+		Expect(ts.LastMessage.Level).To(Equal("INFO"))
+		Expect(ts.LastMessage.Msg).To(Equal("Hello, world!"))
+		Expect(ts.LastMessage.Name).To(Equal("SlogService"))
+
+		sl.Warn("warn")
+		Expect(ts.LastMessage.Level).To(Equal("WARN"))
+
+		sl.Error("error")
+		Expect(ts.LastMessage.Level).To(Equal("ERROR"))
+
+		sl.Debug("debug")
+		Expect(ts.LastMessage.Level).To(Equal("DEBUG"))
+
+		// Below the logger's level
+		sl.Log(context.Background(), slog.LevelDebug-4, "trace")
+		Expect(ts.LastMessage.Msg).To(Equal("debug"))
+
+		// Log of an ecl logger writing to the slog.Logger
+		logger.FromSlog(sl.WithGroup("request")).Log("log")
+		Expect(ts.LastMessage.Level).To(Equal("LOG"))
+	})
+
+	It("Test attrs and groups", func() {
+		sl.With("service", "shop").
+			WithGroup("request").
+			Info("Hello, world!",
+				slog.Int("id", 7),
+				slog.Group("user", slog.String("name", "john")),
+				slog.Any("err", errors.New("boom")),
+			)
+
+		fields := map[string]string{}
+		for _, f := range ts.LastMessage.Fields {
+			fields[f.Key] = f.ValueString()
+		}
+
+		Expect(fields).To(Equal(map[string]string{
+			"service":           "shop",
+			"request.id":        "7",
+			"request.user.name": "john",
+			"request.err":       "boom",
+		}))
+	})
+})
@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+type (
+	// Logger that writes to a slog.Logger. The logger name is added as the "logger" attr
+	slogLogger struct {
+		logger *slog.Logger
+		name   string
+
+		// Context passed to the handler. Set by Ctx
+		ctx context.Context
+	}
+)
+
+// slog levels of the ecl levels. slog has no Trace, Fatal and Panic, so these are placed below Debug and above Error.
+// Log is placed above Panic so it is not filtered by the level of the slog handler, as in ecl
+const (
+	slogLevelTrace = slog.LevelDebug - 4
+	slogLevelFatal = slog.LevelError + 4
+	slogLevelPanic = slog.LevelError + 8
+	slogLevelLog   = slog.LevelError + 12
+)
+
+// Convert the field to a slog attr, keeping the value type
+func fieldToAttr(f message.Field) slog.Attr {
+	switch f.Type {
+	case message.StringType:
+		if v, ok := f.Value.(string); ok {
+			return slog.String(f.Key, v)
+		}
+	case message.IntType:
+		if v, ok := f.Value.(int64); ok {
+			return slog.Int64(f.Key, v)
+		}
+	case message.FloatType:
+		if v, ok := f.Value.(float64); ok {
+			return slog.Float64(f.Key, v)
+		}
+	case message.BoolType:
+		if v, ok := f.Value.(bool); ok {
+			return slog.Bool(f.Key, v)
+		}
+	case message.DurationType:
+		if v, ok := f.Value.(time.Duration); ok {
+			return slog.Duration(f.Key, v)
+		}
+	case message.TimeType:
+		if v, ok := f.Value.(time.Time); ok {
+			return slog.Time(f.Key, v)
+		}
+	}
+
+	// Other types and the values not matching the type (e.g. a hand-built Field)
+	return slog.Any(f.Key, f.Value)
+}
+
+func (l *slogLogger) context() context.Context {
+	if l.ctx == nil {
+		return context.Background()
+	}
+	return l.ctx
+}
+
+// Get the program counter of the caller of the public log method (runtime.Callers, callerPC, log/logf, Info/Infof)
+func callerPC() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(4, pcs[:])
+	return pcs[0]
+}
+
+// Write the record with the caller's program counter, so the source of the slog handler points to the caller
+func (l *slogLogger) logRecord(pc uintptr, level slog.Level, msg string, fields []message.Field) {
+	r := slog.NewRecord(time.Now(), level, msg, pc)
+	if l.name != "" {
+		r.AddAttrs(slog.String("logger", l.name))
+	}
+	for _, f := range fields {
+		r.AddAttrs(fieldToAttr(f))
+	}
+
+	l.logger.Handler().Handle(l.context(), r)
+}
+
+func (l *slogLogger) log(level slog.Level, msg string, fields []message.Field) {
+	if !l.logger.Enabled(l.context(), level) {
+		return
+	}
+
+	l.logRecord(callerPC(), level, msg, fields)
+}
+
+func (l *slogLogger) logf(level slog.Level, format string, args ...interface{}) {
+	// Skip the formatting if the level is disabled
+	if !l.logger.Enabled(l.context(), level) {
+		return
+	}
+
+	args, fields := splitFieldArgs(args)
+	l.logRecord(callerPC(), level, fmt.Sprintf(format, args...), fields)
+}
+
+// Flush and close every registered ecl stream as Shutdown does, then exit the program with code 1
+func (l *slogLogger) exit() {
+	Shutdown(context.Background())
+	exitFunc(1)
+}
+
+func (l *slogLogger) Log(msg string, fields ...message.Field) {
+	l.log(slogLevelLog, msg, fields)
+}
+
+func (l *slogLogger) Trace(msg string, fields ...message.Field) {
+	l.log(slogLevelTrace, msg, fields)
+}
+
+func (l *slogLogger) Debug(msg string, fields ...message.Field) {
+	l.log(slog.LevelDebug, msg, fields)
+}
+
+func (l *slogLogger) Info(msg string, fields ...message.Field) {
+	l.log(slog.LevelInfo, msg, fields)
+}
+
+func (l *slogLogger) Warn(msg string, fields ...message.Field) {
+	l.log(slog.LevelWarn, msg, fields)
+}
+
+func (l *slogLogger) Error(msg string, fields ...message.Field) {
+	l.log(slog.LevelError, msg, fields)
+}
+
+func (l *slogLogger) Fatal(msg string, fields ...message.Field) {
+	l.log(slogLevelFatal, msg, fields)
+	l.exit()
+}
+
+func (l *slogLogger) Panic(msg string, fields ...message.Field) {
+	l.log(slogLevelPanic, msg, fields)
+	panic(msg)
+}
+
+func (l *slogLogger) Logf(format string, args ...interface{}) {
+	l.logf(slogLevelLog, format, args...)
+}
+
+func (l *slogLogger) Tracef(format string, args ...interface{}) {
+	l.logf(slogLevelTrace, format, args...)
+}
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.logf(slog.LevelDebug, format, args...)
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.logf(slog.LevelInfo, format, args...)
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.logf(slog.LevelWarn, format, args...)
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.logf(slog.LevelError, format, args...)
+}
+
+func (l *slogLogger) Fatalf(format string, args ...interface{}) {
+	l.logf(slogLevelFatal, format, args...)
+	l.exit()
+}
+
+func (l *slogLogger) Panicf(format string, args ...interface{}) {
+	args, fields := splitFieldArgs(args)
+	msg := fmt.Sprintf(format, args...)
+	l.log(slogLevelPanic, msg, fields)
+	panic(msg)
+}
+
+func (l *slogLogger) With(fields ...message.Field) Logger {
+	attrs := make([]any, 0, len(fields))
+	for _, f := range fields {
+		attrs = append(attrs, fieldToAttr(f))
+	}
+
+	return &slogLogger{
+		logger: l.logger.With(attrs...),
+		name:   l.name,
+		ctx:    l.ctx,
+	}
+}
+
+func (l *slogLogger) Named(sub string) Logger {
+	child := *l
+	switch {
+	case l.name == "":
+		child.name = sub
+	case sub != "":
+		child.name = l.name + "." + sub
+	}
+	return &child
+}
+
+func (l *slogLogger) Slog() *slog.Logger {
+	if l.name == "" {
+		return l.logger
+	}
+	return l.logger.With(slog.String("logger", l.name))
+}
+
+// Get a Logger that writes to the slog.Logger.
+// If the slog.Logger is backed by an ecl SlogHandler, the underlying ecl logger is returned as is
+func FromSlog(sl *slog.Logger) Logger {
+	if h, ok := sl.Handler().(*SlogHandler); ok && h.groupPrefix == "" {
+		return h.logger
+	}
+
+	return &slogLogger{
+		logger: sl,
+	}
+}
+
+// Get a slog.Logger that writes to the logger's streams with the logger's name, app name, level and bound fields
+func (l *LoggerImpl) Slog() *slog.Logger {
+	return slog.New(&SlogHandler{
+		logger: l,
+	})
+}
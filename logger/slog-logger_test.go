@@ -0,0 +1,117 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Slog Logger", func() {
+	It("Test Logger.Slog", func() {
+		ts := &TestStream{}
+		l := logger.NewLogger(logger.LoggerOption{
+			Name:     "OrderService",
+			AppName:  "Shop",
+			Silent:   true,
+			LogLevel: logger.Info,
+			ExtraStreams: []stream.ILogStream{
+				ts,
+			},
+		})
+
+		sl := l.With(message.String("request_id", "abc")).Slog()
+		sl.Info("Hello, world!", "count", 1)
+
+		m := ts.LastMessage
+		Expect(m.Name).To(Equal("OrderService"))
+		Expect(m.AppName).To(Equal("Shop"))
+		Expect(m.Level).To(Equal("INFO"))
+		Expect(m.Fields).To(HaveLen(2))
+		Expect(m.Fields[0].Key).To(Equal("request_id"))
+
+		// Level of the ecl logger is kept
+		sl.Debug("debug")
+		Expect(ts.LastMessage.Msg).To(Equal("Hello, world!"))
+
+		// Round trip returns the ecl logger
+		logger.FromSlog(sl).Warn("warn")
+		Expect(ts.LastMessage.Level).To(Equal("WARN"))
+		Expect(ts.LastMessage.Name).To(Equal("OrderService"))
+	})
+
+	It("Test FromSlog", func() {
+		var buf bytes.Buffer
+		sl := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		}))
+
+		l := logger.FromSlog(sl).Named("OrderService").Named("checkout")
+
+		// Below the slog level
+		l.Debugf("debug %d", 1)
+		Expect(buf.Len()).To(BeZero())
+
+		l.With(message.Int("count", 2)).Warnf("Hello, %s!", "world", message.Bool("ok", true))
+
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &parsed)).To(Succeed())
+		Expect(parsed["level"]).To(Equal("WARN"))
+		Expect(parsed["msg"]).To(Equal("Hello, world!"))
+		Expect(parsed["logger"]).To(Equal("OrderService.checkout"))
+		Expect(parsed["count"]).To(BeNumerically("==", 2))
+		Expect(parsed["ok"]).To(BeTrue())
+	})
+
+	It("Test FromSlog Log and Fatal", func() {
+		var buf bytes.Buffer
+		l := logger.FromSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelWarn,
+		})))
+
+		// Log is never filtered, as in ecl
+		l.Logf("log %d", 1)
+
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &parsed)).To(Succeed())
+		Expect(parsed["msg"]).To(Equal("log 1"))
+
+		// The ecl streams are closed before exiting
+		ts := &ClosableTestStream{}
+		logger.NewLogger(logger.LoggerOption{
+			Silent: true,
+			ExtraStreams: []stream.ILogStream{
+				ts,
+			},
+		})
+
+		exitCode := -1
+		logger.SetExitFunc(func(code int) {
+			exitCode = code
+		})
+		defer logger.SetExitFunc(nil)
+
+		l.Fatal("fatal")
+		Expect(ts.Calls).To(Equal([]string{"flush", "close"}))
+		Expect(exitCode).To(Equal(1))
+	})
+
+	It("Test FromSlog with hand-built fields", func() {
+		var buf bytes.Buffer
+		l := logger.FromSlog(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+		// Values not matching the type must not panic
+		Expect(func() {
+			l.Info("hand-built", message.Field{Key: "count", Type: message.IntType, Value: 3})
+		}).NotTo(Panic())
+
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &parsed)).To(Succeed())
+		Expect(parsed["count"]).To(BeNumerically("==", 3))
+	})
+})
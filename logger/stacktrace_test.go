@@ -0,0 +1,60 @@
+package logger_test
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stacktrace", func() {
+	ts := &TestStream{}
+	option := logger.LoggerOption{
+		Name:          "StacktraceTest",
+		Silent:        true,
+		AddStacktrace: true,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	}
+	l := logger.NewLogger(option)
+
+	// The first frame must be the caller, with the ecl frames trimmed out
+	expectStacktraceOfCaller := func(stacktrace string) {
+		Expect(stacktrace).NotTo(BeEmpty())
+
+		firstFrame := strings.SplitN(stacktrace, "\n", 3)
+		Expect(firstFrame[0]).To(ContainSubstring("logger_test"))
+		Expect(firstFrame[1]).To(ContainSubstring("stacktrace_test.go"))
+		Expect(stacktrace).NotTo(ContainSubstring("ecl/logger.(*LoggerImpl)"))
+	}
+
+	It("Test stack trace at or above the level", func() {
+		l.Warn("warn")
+		Expect(ts.LastMessage.Stacktrace).To(BeEmpty())
+
+		l.Error("error")
+		expectStacktraceOfCaller(ts.LastMessage.Stacktrace)
+
+		l.Errorf("error %d", 1)
+		expectStacktraceOfCaller(ts.LastMessage.Stacktrace)
+
+		Expect(func() { l.Panic("panic") }).To(Panic())
+		expectStacktraceOfCaller(ts.LastMessage.Stacktrace)
+	})
+
+	It("Test StacktraceLevel", func() {
+		o := option
+		o.StacktraceLevel = logger.Warn
+		logger.NewLogger(o).Warn("warn")
+		expectStacktraceOfCaller(ts.LastMessage.Stacktrace)
+	})
+
+	It("Test stack trace through slog", func() {
+		slog.New(logger.NewSlogHandler(option)).Error("error")
+		expectStacktraceOfCaller(ts.LastMessage.Stacktrace)
+	})
+})
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"log"
+	"strings"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+type (
+	// io.Writer that writes each line of a standard library log.Logger as a log message
+	StdLogWriter struct {
+		logger Logger
+		level  LogLevel
+
+		// The std-lib logger writing to this writer. If set, its prefix and header (date, time, file) are parsed off
+		stdLogger *log.Logger
+	}
+)
+
+// Frames between the caller of the std-lib log function and the Logger (log.Printf, log.(*Logger).output, Write, logAtLevel)
+const stdLogCallerSkip = 4
+
+// Write the message with the method of the level. All is written with Log
+func logAtLevel(l Logger, level LogLevel, msg string, fields []message.Field) {
+	switch level {
+	case Trace:
+		l.Trace(msg, fields...)
+	case Debug:
+		l.Debug(msg, fields...)
+	case Info:
+		l.Info(msg, fields...)
+	case Warn:
+		l.Warn(msg, fields...)
+	case Error:
+		l.Error(msg, fields...)
+	default:
+		l.Log(msg, fields...)
+	}
+}
+
+// Remove the prefix and the header written by the std-lib logger. The file:line of the header is returned as a "caller" field
+func parseStdLogLine(line, prefix string, flags int) (string, []message.Field) {
+	var fields []message.Field
+
+	if flags&log.Lmsgprefix == 0 {
+		line = strings.TrimPrefix(line, prefix)
+	}
+
+	// <date> <time>[.micro] <file>:<line>: (see log.formatHeader)
+	if flags&log.Ldate != 0 && len(line) >= len("2006/01/02 ") {
+		line = line[len("2006/01/02 "):]
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		timeLen := len("15:04:05 ")
+		if flags&log.Lmicroseconds != 0 {
+			timeLen = len("15:04:05.000000 ")
+		}
+		if len(line) >= timeLen {
+			line = line[timeLen:]
+		}
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		if i := strings.Index(line, ": "); i >= 0 {
+			fields = append(fields, message.String("caller", line[:i]))
+			line = line[i+2:]
+		}
+	}
+
+	if flags&log.Lmsgprefix != 0 {
+		line = strings.TrimPrefix(line, prefix)
+	}
+
+	return line, fields
+}
+
+// Each Write of a log.Logger is a single log line
+func (w *StdLogWriter) Write(p []byte) (int, error) {
+	line := strings.TrimSuffix(string(p), "\n")
+
+	var fields []message.Field
+	if w.stdLogger != nil {
+		line, fields = parseStdLogLine(line, w.stdLogger.Prefix(), w.stdLogger.Flags())
+	}
+
+	logAtLevel(w.logger, w.level, line, fields)
+
+	return len(p), nil
+}
+
+// Create an io.Writer that writes each line as a log message of the level.
+// The lines are written as is, so use it with a log.Logger without prefix and flags (or use NewStdLog)
+func NewStdLogWriter(l Logger, level LogLevel) *StdLogWriter {
+	return &StdLogWriter{
+		logger: addCallerSkip(l, stdLogCallerSkip),
+		level:  level,
+	}
+}
+
+// Create a standard library log.Logger that writes through the logger. (e.g. for http.Server.ErrorLog)
+func NewStdLog(l Logger, level LogLevel) *log.Logger {
+	return log.New(NewStdLogWriter(l, level), "", 0)
+}
+
+// Redirect the output of the standard library log package to the logger.
+// The prefix and the flags set on the log package are parsed off each line
+func RedirectStdLog(l Logger, level LogLevel) {
+	log.SetOutput(&StdLogWriter{
+		logger:    addCallerSkip(l, stdLogCallerSkip),
+		level:     level,
+		stdLogger: log.Default(),
+	})
+}
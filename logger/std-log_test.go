@@ -0,0 +1,58 @@
+package logger_test
+
+import (
+	"log"
+	"os"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Std Log", func() {
+	ts := &TestStream{}
+	l := logger.NewLogger(logger.LoggerOption{
+		Name:   "StdLog",
+		Silent: true,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	})
+
+	It("Test RedirectStdLog", func() {
+		prefix, flags := log.Prefix(), log.Flags()
+		DeferCleanup(func() {
+			log.SetOutput(os.Stderr)
+			log.SetPrefix(prefix)
+			log.SetFlags(flags)
+		})
+
+		logger.RedirectStdLog(l, logger.Warn)
+		log.SetPrefix("[lib] ")
+		log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+
+		log.Printf("Hello, %s!", "world")
+
+		m := ts.LastMessage
+		Expect(m.Level).To(Equal("WARN"))
+		Expect(m.Msg).To(Equal("Hello, world!"))
+		Expect(m.Fields).To(HaveLen(1))
+		Expect(m.Fields[0].Key).To(Equal("caller"))
+		Expect(m.Fields[0].ValueString()).To(MatchRegexp(`^std-log_test\.go:\d+$`))
+
+		// Prefix placed before the message
+		log.SetFlags(log.LstdFlags | log.Lmsgprefix)
+		log.Print("with msg prefix")
+		Expect(ts.LastMessage.Msg).To(Equal("with msg prefix"))
+	})
+
+	It("Test NewStdLog", func() {
+		sl := logger.NewStdLog(l, logger.Error)
+		sl.Println("http: TLS handshake error")
+
+		m := ts.LastMessage
+		Expect(m.Level).To(Equal("ERROR"))
+		Expect(m.Msg).To(Equal("http: TLS handshake error"))
+	})
+})
@@ -0,0 +1,61 @@
+package message
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Where the log was emitted
+	Caller struct {
+		File     string
+		Line     int
+		Function string
+	}
+)
+
+// Get the caller of the program counter
+func CallerFromPC(pc uintptr) Caller {
+	if pc == 0 {
+		return Caller{}
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return Caller{
+		File:     frame.File,
+		Line:     frame.Line,
+		Function: frame.Function,
+	}
+}
+
+// True if the caller was captured
+func (c Caller) Defined() bool {
+	return c.File != ""
+}
+
+// file.go:42
+func (c Caller) String() string {
+	if !c.Defined() {
+		return ""
+	}
+	return filepath.Base(c.File) + ":" + strconv.Itoa(c.Line)
+}
+
+// The function without the package path and pointer receiver marks (e.g. OrderService.checkout) like Logback's %C{1}.%M
+func (c Caller) ShortFunction() string {
+	fn := c.Function
+
+	// github.com/x/app/service.(*OrderService).checkout --> service.(*OrderService).checkout
+	if i := strings.LastIndex(fn, "/"); i >= 0 {
+		fn = fn[i+1:]
+	}
+
+	// Methods: service.(*OrderService).checkout --> OrderService.checkout
+	if i := strings.Index(fn, "."); i >= 0 && strings.Contains(fn[i+1:], ".") {
+		fn = fn[i+1:]
+	}
+
+	return strings.NewReplacer("(*", "", ")", "").Replace(fn)
+}
@@ -0,0 +1,60 @@
+package message
+
+import (
+	"errors"
+	"strings"
+)
+
+type (
+	// One error in the cause chain of an error
+	ErrorCause struct {
+		Msg string
+
+		// 0 for the error itself (and each error of a errors.Join), +1 for each wrap
+		Depth int
+	}
+)
+
+// Walk the errors.Unwrap and errors.Join chain of the error, depth first.
+// The errors.Join node itself is skipped as its message is just the messages of the joined errors
+func ErrorChain(err error) []ErrorCause {
+	var chain []ErrorCause
+	walkErrorChain(err, 0, &chain)
+	return chain
+}
+
+func walkErrorChain(err error, depth int, chain *[]ErrorCause) {
+	if err == nil {
+		return
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		joined := e.Unwrap()
+
+		// Keep the message of the multi %w wrappers (fmt.Errorf), skip the plain joins
+		if !isJoinMessage(err.Error(), joined) {
+			*chain = append(*chain, ErrorCause{Msg: err.Error(), Depth: depth})
+			depth++
+		}
+
+		for _, je := range joined {
+			walkErrorChain(je, depth, chain)
+		}
+	default:
+		*chain = append(*chain, ErrorCause{Msg: err.Error(), Depth: depth})
+		walkErrorChain(errors.Unwrap(err), depth+1, chain)
+	}
+}
+
+// True if the message is the errors.Join message of the errors
+func isJoinMessage(msg string, joined []error) bool {
+	msgs := make([]string, 0, len(joined))
+	for _, je := range joined {
+		if je != nil {
+			msgs = append(msgs, je.Error())
+		}
+	}
+
+	return msg == strings.Join(msgs, "\n")
+}
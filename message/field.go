@@ -0,0 +1,113 @@
+package message
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type (
+	FieldType int
+
+	// A typed key/value pair attached to a log message
+	Field struct {
+		Key   string
+		Type  FieldType
+		Value interface{}
+	}
+)
+
+const (
+	UnknownType FieldType = iota
+	StringType
+	IntType
+	FloatType
+	BoolType
+	DurationType
+	TimeType
+	ErrorType
+	ObjectType
+)
+
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, Value: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Value: int64(value)}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Value: value}
+}
+
+func Float(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Value: value}
+}
+
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Type: BoolType, Value: value}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Value: value}
+}
+
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Value: value}
+}
+
+// Error field. The key is always "error"
+func Err(err error) Field {
+	return Field{Key: "error", Type: ErrorType, Value: err}
+}
+
+// Error field with a custom key
+func NamedErr(key string, err error) Field {
+	return Field{Key: key, Type: ErrorType, Value: err}
+}
+
+// Arbitrary value. The value is rendered with fmt's %v verb
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Type: ObjectType, Value: value}
+}
+
+// Get the human readable string of the field value.
+// Values not matching the type (e.g. a hand-built Field) are rendered with fmt's %v verb
+func (f Field) ValueString() string {
+	switch f.Type {
+	case StringType:
+		if v, ok := f.Value.(string); ok {
+			return v
+		}
+	case IntType:
+		if v, ok := f.Value.(int64); ok {
+			return strconv.FormatInt(v, 10)
+		}
+	case FloatType:
+		if v, ok := f.Value.(float64); ok {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	case BoolType:
+		if v, ok := f.Value.(bool); ok {
+			return strconv.FormatBool(v)
+		}
+	case DurationType:
+		if v, ok := f.Value.(time.Duration); ok {
+			return v.String()
+		}
+	case TimeType:
+		if v, ok := f.Value.(time.Time); ok {
+			return v.Format(time.RFC3339Nano)
+		}
+	case ErrorType:
+		if f.Value == nil {
+			return "<nil>"
+		}
+		if v, ok := f.Value.(error); ok {
+			return v.Error()
+		}
+	}
+
+	return fmt.Sprintf("%v", f.Value)
+}
@@ -10,5 +10,18 @@ type (
 		Color   string
 		Level   string
 		Msg     string
+
+		// Structured key/value context of the message
+		Fields []Field
+
+		// Where the log was emitted. Only set if the logger's AddCaller option is set
+		Caller Caller
+
+		// IDs of the active trace span (hex). Only set for loggers from Logger.Ctx with a trace context extractor
+		TraceID string
+		SpanID  string
+
+		// Stack trace of the caller ("function\n\tfile:line" per frame). Only set if the logger's AddStacktrace option is set
+		Stacktrace string
 	}
 )
@@ -0,0 +1,23 @@
+package otel
+
+import (
+	"context"
+
+	"github.com/jhseong7/ecl/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Get the trace and span IDs (hex) of the active OpenTelemetry span of the context. Empty strings if there is no valid span
+func TraceContextExtractor(ctx context.Context) (string, string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+
+	return sc.TraceID().String(), sc.SpanID().String()
+}
+
+// Add the IDs of the active OpenTelemetry span to the logs of Logger.Ctx and the slog ...Context methods
+func Install() {
+	logger.SetTraceContextExtractor(TraceContextExtractor)
+}
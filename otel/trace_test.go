@@ -0,0 +1,74 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jhseong7/ecl/logger"
+	"github.com/jhseong7/ecl/message"
+	eclotel "github.com/jhseong7/ecl/otel"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type TestStream struct {
+	stream.ILogStream
+	LastMessage message.LogMessage
+}
+
+func (s *TestStream) Write(msg message.LogMessage) {
+	s.LastMessage = msg
+}
+
+var _ = Describe("OpenTelemetry trace", func() {
+	eclotel.Install()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+
+	ts := &TestStream{}
+	l := logger.NewLogger(logger.LoggerOption{
+		Name:   "TraceTest",
+		Silent: true,
+		ExtraStreams: []stream.ILogStream{
+			ts,
+		},
+	})
+
+	It("Test trace and span IDs of Logger.Ctx", func() {
+		ctx, span := tracer.Start(context.Background(), "checkout")
+		l.Ctx(ctx).Info("Hello, world!")
+		span.End()
+
+		// The IDs of the log match the exported span
+		spans := exporter.GetSpans()
+		Expect(spans).NotTo(BeEmpty())
+		exported := spans[len(spans)-1].SpanContext
+
+		Expect(ts.LastMessage.TraceID).To(Equal(exported.TraceID().String()))
+		Expect(ts.LastMessage.SpanID).To(Equal(exported.SpanID().String()))
+	})
+
+	It("Test trace and span IDs of slog InfoContext", func() {
+		ctx, span := tracer.Start(context.Background(), "checkout")
+		defer span.End()
+
+		l.Slog().InfoContext(ctx, "Hello, world!")
+		Expect(ts.LastMessage.TraceID).To(Equal(span.SpanContext().TraceID().String()))
+		Expect(ts.LastMessage.SpanID).To(Equal(span.SpanContext().SpanID().String()))
+	})
+
+	It("Test no IDs without a span", func() {
+		l.Ctx(context.Background()).Info("Hello, world!")
+		Expect(ts.LastMessage.TraceID).To(BeEmpty())
+		Expect(ts.LastMessage.SpanID).To(BeEmpty())
+	})
+})
+
+func TestOtel(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Otel Suite")
+}
@@ -0,0 +1,213 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+type (
+	// What to do when the queue of the AsyncStream is full
+	OverflowPolicy int
+
+	AsyncStreamOption struct {
+		// Max number of queued messages. Default is 1024
+		QueueSize int
+
+		// Default is BlockOnOverflow
+		OverflowPolicy OverflowPolicy
+	}
+
+	// Wraps a stream so the messages are written by a dedicated goroutine instead of the caller's goroutine
+	AsyncStream struct {
+		ILogStream
+
+		// The wrapped stream
+		stream ILogStream
+
+		policy OverflowPolicy
+
+		// Ring buffer of the queued messages
+		queue []message.LogMessage
+		head  int
+		count int
+
+		// True while the writer goroutine is writing a message to the wrapped stream
+		writing bool
+
+		closed  bool
+		dropped uint64
+
+		mutex    *sync.Mutex
+		notEmpty *sync.Cond
+		notFull  *sync.Cond
+		idle     *sync.Cond
+
+		// Closed when the writer goroutine exits
+		done chan struct{}
+	}
+)
+
+const (
+	// Block the caller until there is room in the queue
+	BlockOnOverflow OverflowPolicy = iota
+
+	// Drop the message being written
+	DropNewest
+
+	// Drop the oldest queued message to make room
+	DropOldest
+)
+
+const defaultAsyncQueueSize = 1024
+
+func (s *AsyncStream) push(msg message.LogMessage) {
+	s.queue[(s.head+s.count)%len(s.queue)] = msg
+	s.count++
+}
+
+func (s *AsyncStream) pop() message.LogMessage {
+	msg := s.queue[s.head]
+	s.queue[s.head] = message.LogMessage{}
+	s.head = (s.head + 1) % len(s.queue)
+	s.count--
+	return msg
+}
+
+// Queue the message. The message is written to the wrapped stream by the writer goroutine
+func (s *AsyncStream) Write(msg message.LogMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.count == len(s.queue) && !s.closed {
+		switch s.policy {
+		case DropNewest:
+			s.dropped++
+			return
+		case DropOldest:
+			s.pop()
+			s.dropped++
+		default:
+			for s.count == len(s.queue) && !s.closed {
+				s.notFull.Wait()
+			}
+		}
+	}
+
+	// Writes after Close are dropped
+	if s.closed {
+		s.dropped++
+		return
+	}
+
+	s.push(msg)
+	s.notEmpty.Signal()
+}
+
+// Write the queued messages to the wrapped stream until the stream is closed and the queue is empty
+func (s *AsyncStream) run() {
+	defer close(s.done)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for {
+		for s.count == 0 && !s.closed {
+			s.notEmpty.Wait()
+		}
+
+		if s.count == 0 {
+			// Closed and nothing left to write
+			return
+		}
+
+		msg := s.pop()
+		s.writing = true
+		s.notFull.Signal()
+
+		// Write without the lock so the callers are not blocked by the wrapped stream
+		s.mutex.Unlock()
+		s.stream.Write(msg)
+		s.mutex.Lock()
+
+		s.writing = false
+		if s.count == 0 {
+			s.idle.Broadcast()
+		}
+	}
+}
+
+// Number of messages dropped by the overflow policy or written after Close
+func (s *AsyncStream) Dropped() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.dropped
+}
+
+// Wait until all queued messages are written, then flush the wrapped stream
+func (s *AsyncStream) Flush() error {
+	s.mutex.Lock()
+	for (s.count > 0 || s.writing) && !s.closed {
+		s.idle.Wait()
+	}
+	s.mutex.Unlock()
+
+	return Flush(s.stream)
+}
+
+// Write all queued messages, stop the writer goroutine, then flush and close the wrapped stream.
+// Writes after Close are dropped
+func (s *AsyncStream) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.notEmpty.Broadcast()
+	s.notFull.Broadcast()
+	s.idle.Broadcast()
+	s.mutex.Unlock()
+
+	// Wait for the writer to drain the queue
+	<-s.done
+
+	if err := Flush(s.stream); err != nil {
+		Close(s.stream)
+		return err
+	}
+	return Close(s.stream)
+}
+
+func NewAsyncStream(s ILogStream, options ...AsyncStreamOption) *AsyncStream {
+	// if len > 1, then it's an error
+	if len(options) > 1 {
+		panic("NewAsyncStream: Too many options")
+	}
+
+	var option AsyncStreamOption
+	if len(options) == 1 {
+		option = options[0]
+	}
+
+	if option.QueueSize <= 0 {
+		option.QueueSize = defaultAsyncQueueSize
+	}
+
+	mutex := &sync.Mutex{}
+	as := &AsyncStream{
+		stream:   s,
+		policy:   option.OverflowPolicy,
+		queue:    make([]message.LogMessage, option.QueueSize),
+		mutex:    mutex,
+		notEmpty: sync.NewCond(mutex),
+		notFull:  sync.NewCond(mutex),
+		idle:     sync.NewCond(mutex),
+		done:     make(chan struct{}),
+	}
+
+	go as.run()
+
+	return as
+}
@@ -0,0 +1,112 @@
+package stream_test
+
+import (
+	"sync"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Stream that blocks every write until the gate is opened
+type GatedStream struct {
+	started chan struct{}
+	gate    chan struct{}
+
+	mutex    sync.Mutex
+	messages []string
+	closed   bool
+}
+
+func newGatedStream() *GatedStream {
+	return &GatedStream{
+		started: make(chan struct{}, 100),
+		gate:    make(chan struct{}),
+	}
+}
+
+func (s *GatedStream) Write(msg message.LogMessage) {
+	s.started <- struct{}{}
+	<-s.gate
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.messages = append(s.messages, msg.Msg)
+}
+
+func (s *GatedStream) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *GatedStream) Messages() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.messages...)
+}
+
+var _ = Describe("Async Stream", func() {
+	// Fill the queue of size 2 while the writer is blocked on the first message
+	fill := func(policy stream.OverflowPolicy) (*GatedStream, *stream.AsyncStream) {
+		gs := newGatedStream()
+		as := stream.NewAsyncStream(gs, stream.AsyncStreamOption{
+			QueueSize:      2,
+			OverflowPolicy: policy,
+		})
+
+		as.Write(newTestMessage("m0"))
+		<-gs.started
+
+		as.Write(newTestMessage("m1"))
+		as.Write(newTestMessage("m2"))
+		as.Write(newTestMessage("m3"))
+
+		return gs, as
+	}
+
+	It("Test DropNewest", func() {
+		gs, as := fill(stream.DropNewest)
+		close(gs.gate)
+
+		Expect(as.Close()).To(Succeed())
+		Expect(gs.Messages()).To(Equal([]string{"m0", "m1", "m2"}))
+		Expect(as.Dropped()).To(BeEquivalentTo(1))
+		Expect(gs.closed).To(BeTrue())
+	})
+
+	It("Test DropOldest", func() {
+		gs, as := fill(stream.DropOldest)
+		close(gs.gate)
+
+		Expect(as.Close()).To(Succeed())
+		Expect(gs.Messages()).To(Equal([]string{"m0", "m2", "m3"}))
+		Expect(as.Dropped()).To(BeEquivalentTo(1))
+	})
+
+	It("Test BlockOnOverflow and Flush", func() {
+		gs := newGatedStream()
+		close(gs.gate)
+
+		as := stream.NewAsyncStream(gs, stream.AsyncStreamOption{
+			QueueSize: 4,
+		})
+
+		for i := 0; i < 100; i++ {
+			as.Write(newTestMessage("msg"))
+		}
+
+		// Nothing is lost and everything is written once Flush returns
+		Expect(as.Flush()).To(Succeed())
+		Expect(gs.Messages()).To(HaveLen(100))
+		Expect(as.Dropped()).To(BeZero())
+
+		// Writes after Close are dropped
+		Expect(as.Close()).To(Succeed())
+		as.Write(newTestMessage("after close"))
+		Expect(gs.Messages()).To(HaveLen(100))
+		Expect(as.Dropped()).To(BeEquivalentTo(1))
+	})
+})
@@ -0,0 +1,163 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+type (
+	// Queued message with the data encoded by the stream (optional). The size of the data counts toward the max bytes of a batch
+	batchEntry struct {
+		msg  message.LogMessage
+		data []byte
+	}
+
+	// Collects the messages and sends them in batches by count or interval on a dedicated goroutine.
+	// Shared by the streams that export to a remote service
+	batcher struct {
+		size     int
+		maxBytes int
+		maxQueue int
+		interval time.Duration
+
+		// Send the batch. Called by one goroutine at a time.
+		// The closing channel is closed when the batcher is closed, so the send must stop waiting (e.g. for a retry)
+		send func(batch []batchEntry, closing <-chan struct{})
+
+		mutex        *sync.Mutex
+		pending      []batchEntry
+		pendingBytes int
+		closed       bool
+		dropped      uint64
+
+		// Serialize the sends of the timer, the size trigger and Flush
+		sendMutex *sync.Mutex
+
+		flushSignal chan struct{}
+		stop        chan struct{}
+		done        chan struct{}
+	}
+)
+
+// Create the batcher. A batch is sent when it has the size messages or the max bytes of data (0 is no limit)
+func newBatcher(size, maxBytes, maxQueue int, interval time.Duration, send func(batch []batchEntry, closing <-chan struct{})) *batcher {
+	b := &batcher{
+		size:        size,
+		maxBytes:    maxBytes,
+		mutex:       &sync.Mutex{},
+		sendMutex:   &sync.Mutex{},
+		maxQueue:    maxQueue,
+		interval:    interval,
+		send:        send,
+		flushSignal: make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	go b.run()
+
+	return b
+}
+
+// Queue the message and its encoded data. The message is dropped if the queue is full or the batcher is closed
+func (b *batcher) add(msg message.LogMessage, data []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed || len(b.pending) >= b.maxQueue {
+		b.dropped++
+		return
+	}
+
+	b.pending = append(b.pending, batchEntry{msg: msg, data: data})
+	b.pendingBytes += len(data)
+
+	// Wake up the sender once a batch is full
+	if len(b.pending) >= b.size || (b.maxBytes > 0 && b.pendingBytes >= b.maxBytes) {
+		select {
+		case b.flushSignal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Get the number of the queued messages of the next batch. A message larger than the max bytes is sent alone
+func (b *batcher) nextBatchLength() int {
+	n, bytes := 0, 0
+	for n < len(b.pending) && n < b.size {
+		size := len(b.pending[n].data)
+		if b.maxBytes > 0 && n > 0 && bytes+size > b.maxBytes {
+			break
+		}
+		bytes += size
+		n++
+	}
+	return n
+}
+
+// Send all queued messages in batches of the size. Returns when the batches are sent
+func (b *batcher) flush() {
+	b.sendMutex.Lock()
+	defer b.sendMutex.Unlock()
+
+	for {
+		b.mutex.Lock()
+		if len(b.pending) == 0 {
+			b.mutex.Unlock()
+			return
+		}
+
+		n := b.nextBatchLength()
+		batch := b.pending[:n:n]
+		b.pending = b.pending[n:]
+		for _, e := range batch {
+			b.pendingBytes -= len(e.data)
+		}
+		b.mutex.Unlock()
+
+		b.send(batch, b.stop)
+	}
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.flush()
+		case <-b.flushSignal:
+			b.flush()
+		case <-b.stop:
+			b.flush()
+			return
+		}
+	}
+}
+
+// Number of messages dropped because the queue was full or the batcher was closed
+func (b *batcher) droppedCount() uint64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.dropped
+}
+
+// Send the remaining messages and stop the goroutine. Messages added after close are dropped
+func (b *batcher) close() {
+	b.mutex.Lock()
+	if b.closed {
+		b.mutex.Unlock()
+		return
+	}
+	b.closed = true
+	b.mutex.Unlock()
+
+	close(b.stop)
+	<-b.done
+}
@@ -0,0 +1,27 @@
+package stream
+
+import "os"
+
+// Open the log file of the date as a roll over would
+func (s *FileLogStream) CreateNewLog(date string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.createNewLog(date)
+}
+
+// Hold the maintenance until the returned function is called
+func (s *FileLogStream) PauseMaintenance() func() {
+	s.maintenanceMutex.Lock()
+	return s.maintenanceMutex.Unlock
+}
+
+// Make the renames of the size rotation fail until the returned function is called
+func FailRenames() func() {
+	renameFile = func(string, string) error {
+		return os.ErrPermission
+	}
+	return func() {
+		renameFile = os.Rename
+	}
+}
@@ -1,10 +1,15 @@
 package stream
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,11 +19,23 @@ import (
 
 type (
 	FileLogStreamOption struct {
-		LogDirectory  string
-		FileName      string
-		FileRollover  bool
+		LogDirectory string
+		FileName     string
+		FileRollover bool
+
+		// If the file reaches this size, it is moved to an indexed name (e.g. app.2026-10-18.1.log) and a new file is created.
+		// 0 means no limit
 		MaxFileSizeKb int
 		LogStyle      style.LogStyle
+
+		// If true, the rotated files are compressed to .log.gz in the background. The active file is never compressed
+		Compress bool
+
+		// Max number of rotated files to keep. The oldest files are removed first. 0 means no limit
+		MaxBackups int
+
+		// Rotated files older than this are removed. 0 means no limit
+		MaxAgeDays int
 	}
 
 	FileLogStream struct {
@@ -30,13 +47,28 @@ type (
 		// File pointer
 		file *os.File
 
+		// Base name of the active file. Kept after Close so the last file is not taken as rotated
+		fileName string
+
+		// Date and size of the current file. Used to decide when to roll over
+		fileDate string
+		fileSize int64
+
 		// Mutex to prevent multiple writes at the same time
 		mutex *sync.Mutex
-	}
-)
 
-var (
-	terminalStyleRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+		// If true, the stream is closed and all writes are dropped
+		closed bool
+
+		// Matches the rotated files of this stream. Used for the retention policy
+		rotatedFileRegex *regexp.Regexp
+
+		// Mutex to prevent multiple compressions and prunes at the same time
+		maintenanceMutex *sync.Mutex
+
+		// Running maintenance goroutines. Close waits for these
+		maintenanceWg sync.WaitGroup
+	}
 )
 
 func (s *FileLogStream) getLogFileName(prefix, date string) string {
@@ -47,20 +79,47 @@ func (s *FileLogStream) getLogFileName(prefix, date string) string {
 	return fmt.Sprintf("%s.log", prefix)
 }
 
-// NOTE: Use this later when rollover is implemented
-// func (s *FileLogStream) getFileSizeKb() int64 {
-// 	fileInfo, err := s.file.Stat()
+// Get the file name of the n-th size rotated file. (e.g. app.2026-10-18.1.log)
+func (s *FileLogStream) getIndexedLogFileName(prefix, date string, index int) string {
+	if s.options.FileRollover {
+		return fmt.Sprintf("%s.%s.%d.log", prefix, date, index)
+	}
+
+	return fmt.Sprintf("%s.%d.log", prefix, index)
+}
+
+// Get the next unused index for the size rotated files of the date
+func (s *FileLogStream) getNextLogFileIndex(date string) int {
+	// The prefix of the indexed names without the index part
+	indexedPrefix := strings.TrimSuffix(s.getIndexedLogFileName(s.options.FileName, date, 0), "0.log")
+
+	entries, err := os.ReadDir(s.options.LogDirectory)
+	if err != nil {
+		return 1
+	}
+
+	maxIndex := 0
+	for _, e := range entries {
+		name := e.Name()
+		name = strings.TrimSuffix(name, ".gz")
+		if !strings.HasPrefix(name, indexedPrefix) || !strings.HasSuffix(name, ".log") {
+			continue
+		}
 
-// 	if err != nil {
-// 		panic(err)
-// 	}
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, indexedPrefix), ".log"))
+		if err == nil && index > maxIndex {
+			maxIndex = index
+		}
+	}
 
-// 	return fileInfo.Size() / 1024
-// }
+	return maxIndex + 1
+}
 
-func (s *FileLogStream) createNewLog() {
+func (s *FileLogStream) createNewLog(date string) {
 	// Close the file
-	s.file.Close()
+	if s.file != nil {
+		s.file.Close()
+	}
 
 	// If the LogDirectory does not exist, create it (recursively)
 	if _, err := os.Stat(s.options.LogDirectory); os.IsNotExist(err) {
@@ -69,7 +128,7 @@ func (s *FileLogStream) createNewLog() {
 
 	// Open a new file
 	f, err := os.OpenFile(
-		path.Join(s.options.LogDirectory, s.getLogFileName(s.options.FileName, time.Now().Format("2006-01-02"))),
+		path.Join(s.options.LogDirectory, s.getLogFileName(s.options.FileName, date)),
 		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
 		0644,
 	)
@@ -78,18 +137,204 @@ func (s *FileLogStream) createNewLog() {
 		panic(err)
 	}
 
+	// Continue the size count of the existing file
+	var size int64
+	if fileInfo, err := f.Stat(); err == nil {
+		size = fileInfo.Size()
+	}
+
 	s.file = f
+	s.fileName = path.Base(f.Name())
+	s.fileDate = date
+	s.fileSize = size
+
+	// Compress and prune the rotated files in the background so the write is not blocked
+	if s.options.Compress || s.options.MaxBackups > 0 || s.options.MaxAgeDays > 0 {
+		s.maintenanceWg.Add(1)
+		go s.maintainLogFiles()
+	}
+}
+
+// Get the base name of the active file.
+// Must be read after listing the directory, as a roll over can create a new active file while the maintenance waits
+func (s *FileLogStream) activeFileName() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.fileName
+}
+
+// Compress and apply the retention policy to the rotated files (everything but the active file)
+func (s *FileLogStream) maintainLogFiles() {
+	defer s.maintenanceWg.Done()
+
+	s.maintenanceMutex.Lock()
+	defer s.maintenanceMutex.Unlock()
+
+	if s.options.Compress {
+		s.compressLogFiles()
+	}
+
+	if s.options.MaxBackups > 0 || s.options.MaxAgeDays > 0 {
+		s.pruneLogFiles()
+	}
+}
+
+// Compress all rotated plain text files to .log.gz
+// Leftovers of an interrupted compression (e.g. process restart) are removed and the compression is redone
+func (s *FileLogStream) compressLogFiles() {
+	entries, err := os.ReadDir(s.options.LogDirectory)
+	if err != nil {
+		return
+	}
+	activeFileName := s.activeFileName()
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || name == activeFileName {
+			continue
+		}
+
+		// Half written archive. The original file still exists, so just remove it
+		if strings.HasSuffix(name, ".gz.tmp") && s.rotatedFileRegex.MatchString(strings.TrimSuffix(name, ".tmp")) {
+			os.Remove(path.Join(s.options.LogDirectory, name))
+			continue
+		}
+
+		if strings.HasSuffix(name, ".log") && s.rotatedFileRegex.MatchString(name) {
+			compressLogFile(path.Join(s.options.LogDirectory, name))
+		}
+	}
 }
 
-func (s *FileLogStream) getFilePointer() *os.File {
+// Compress the file to <src>.gz and remove the source.
+// The archive is written to a temp file and renamed when complete, so a half written archive never has the final name
+func compressLogFile(src string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst := src + ".gz"
+	tmp := dst + ".tmp"
+
+	tmpFile, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(tmpFile)
+	_, err = io.Copy(gz, srcFile)
+	if err == nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Keep the modification time of the original so the retention policy stays correct
+	os.Chtimes(tmp, srcInfo.ModTime(), srcInfo.ModTime())
+
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Remove(src)
+}
+
+// Remove the rotated files that exceed MaxBackups or MaxAgeDays
+func (s *FileLogStream) pruneLogFiles() {
+	entries, err := os.ReadDir(s.options.LogDirectory)
+	if err != nil {
+		return
+	}
+	activeFileName := s.activeFileName()
+
+	// Collect the rotated files (everything but the active file)
+	var rotated []os.FileInfo
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == activeFileName || !s.rotatedFileRegex.MatchString(e.Name()) {
+			continue
+		}
+
+		if info, err := e.Info(); err == nil {
+			rotated = append(rotated, info)
+		}
+	}
+
+	// Newest first
+	sort.Slice(rotated, func(i, j int) bool {
+		return rotated[i].ModTime().After(rotated[j].ModTime())
+	})
+
+	cutoff := time.Now().AddDate(0, 0, -s.options.MaxAgeDays)
+	for i, info := range rotated {
+		tooMany := s.options.MaxBackups > 0 && i >= s.options.MaxBackups
+		tooOld := s.options.MaxAgeDays > 0 && info.ModTime().Before(cutoff)
+
+		if tooMany || tooOld {
+			os.Remove(path.Join(s.options.LogDirectory, info.Name()))
+		}
+	}
+}
+
+// Renames the file. Replaced in the tests to simulate a failed rename
+var renameFile = os.Rename
+
+// Move the current file to the next indexed name and open a fresh file.
+// If the file cannot be moved, the current file is kept and the rotation is retried on the next write
+func (s *FileLogStream) rotateBySize() {
+	s.file.Close()
+
+	currentPath := s.file.Name()
+	rotatedPath := path.Join(
+		s.options.LogDirectory,
+		s.getIndexedLogFileName(s.options.FileName, s.fileDate, s.getNextLogFileIndex(s.fileDate)),
+	)
+
+	if err := renameFile(currentPath, rotatedPath); err != nil {
+		// Reopen the current file. If that also fails, a new file is opened on the next write
+		f, err := os.OpenFile(currentPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			s.file = nil
+			return
+		}
+		s.file = f
+		return
+	}
+
+	s.file = nil
+	s.createNewLog(s.fileDate)
+}
+
+func (s *FileLogStream) getFilePointer(nextWriteSize int) *os.File {
 	// Get the current time, and if the current file is not the same date, close the file and open a new one with the current date
 	currentDate := time.Now().Format("2006-01-02")
-	logFileName := s.getLogFileName(s.options.FileName, currentDate)
 
 	// If there is no file pointer, open a new file
 	// If the file is not the same date, close the file and open a new one
-	if s.file == nil || s.file.Name() != logFileName {
-		s.createNewLog()
+	if s.file == nil || (s.options.FileRollover && s.fileDate != currentDate) {
+		s.createNewLog(currentDate)
+	}
+
+	// If the next write crosses the size limit, move on to a new file
+	// An empty file is always written so a single large message cannot cause an endless rotation
+	if s.options.MaxFileSizeKb > 0 && s.fileSize > 0 && s.fileSize+int64(nextWriteSize) > int64(s.options.MaxFileSizeKb)*1024 {
+		s.rotateBySize()
 	}
 
 	return s.file
@@ -100,14 +345,58 @@ func (s *FileLogStream) Write(msg message.LogMessage) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	file := s.getFilePointer()
+	if s.closed {
+		return
+	}
 
 	// Get the message --> and remove all terminal styleing
 	msgStr := style.GetMessageOfStyle(msg, s.options.LogStyle)
-	msgStr = terminalStyleRegex.ReplaceAllString(msgStr, "")
+	msgStr = style.StripTerminalStyle(msgStr)
+
+	file := s.getFilePointer(len(msgStr))
 
 	// Append the message to the file (with a new line)
-	fmt.Fprint(file, msgStr)
+	n, _ := fmt.Fprint(file, msgStr)
+	s.fileSize += int64(n)
+}
+
+// Commit the written messages to the disk
+func (s *FileLogStream) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Sync()
+}
+
+// Close the file and wait for the background compression/pruning to finish. Writes after Close are dropped
+func (s *FileLogStream) Close() error {
+	s.mutex.Lock()
+
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+
+	var err error
+	if s.file != nil {
+		if err = s.file.Sync(); err == nil {
+			err = s.file.Close()
+		} else {
+			s.file.Close()
+		}
+		s.file = nil
+	}
+
+	// Wait for the running maintenance. The mutex is released first as the maintenance reads the active file name
+	s.mutex.Unlock()
+	s.maintenanceWg.Wait()
+
+	return err
 }
 
 func NewFileLogStream(option FileLogStreamOption) *FileLogStream {
@@ -119,5 +408,9 @@ func NewFileLogStream(option FileLogStreamOption) *FileLogStream {
 	return &FileLogStream{
 		options: option,
 		mutex:   &sync.Mutex{},
+
+		// <FileName>.<date>.log, <FileName>.<date>.<index>.log or <FileName>.<index>.log (and the compressed .log.gz)
+		rotatedFileRegex: regexp.MustCompile("^" + regexp.QuoteMeta(option.FileName) + `\.(\d{4}-\d{2}-\d{2}(\.\d+)?|\d+)\.log(\.gz)?$`),
+		maintenanceMutex: &sync.Mutex{},
 	}
 }
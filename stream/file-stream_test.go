@@ -0,0 +1,244 @@
+package stream_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func newTestMessage(msg string) message.LogMessage {
+	return message.LogMessage{
+		AppName: "ECL",
+		Time:    time.Now(),
+		Name:    "test",
+		Level:   "INFO",
+		Msg:     msg,
+	}
+}
+
+var _ = Describe("File Log Stream", func() {
+	It("Test size rotation", func() {
+		dir := GinkgoT().TempDir()
+		s := stream.NewFileLogStream(stream.FileLogStreamOption{
+			LogDirectory:  dir,
+			FileName:      "app",
+			FileRollover:  true,
+			MaxFileSizeKb: 1,
+		})
+
+		// Each message is ~300 bytes --> 3 messages per file
+		for i := 0; i < 10; i++ {
+			s.Write(newTestMessage(strings.Repeat("x", 200)))
+		}
+
+		date := time.Now().Format("2006-01-02")
+		for _, name := range []string{"app." + date + ".log", "app." + date + ".1.log", "app." + date + ".2.log", "app." + date + ".3.log"} {
+			info, err := os.Stat(path.Join(dir, name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Size()).To(BeNumerically("<=", 1024))
+		}
+	})
+
+	It("Test size rotation continues the index after a restart", func() {
+		dir := GinkgoT().TempDir()
+		option := stream.FileLogStreamOption{
+			LogDirectory:  dir,
+			FileName:      "app",
+			MaxFileSizeKb: 1,
+		}
+
+		s := stream.NewFileLogStream(option)
+		for i := 0; i < 5; i++ {
+			s.Write(newTestMessage(strings.Repeat("x", 200)))
+		}
+
+		// A new stream on the same directory must not overwrite app.1.log
+		s = stream.NewFileLogStream(option)
+		for i := 0; i < 5; i++ {
+			s.Write(newTestMessage(strings.Repeat("x", 200)))
+		}
+
+		Expect(path.Join(dir, "app.log")).To(BeAnExistingFile())
+		Expect(path.Join(dir, "app.1.log")).To(BeAnExistingFile())
+		Expect(path.Join(dir, "app.2.log")).To(BeAnExistingFile())
+		Expect(path.Join(dir, "app.3.log")).To(BeAnExistingFile())
+	})
+
+	It("Test failed size rotation", func() {
+		dir := GinkgoT().TempDir()
+		s := stream.NewFileLogStream(stream.FileLogStreamOption{
+			LogDirectory:  dir,
+			FileName:      "app",
+			MaxFileSizeKb: 1,
+		})
+		DeferCleanup(s.Close)
+
+		// The messages are kept in the current file
+		restore := stream.FailRenames()
+		for i := 0; i < 5; i++ {
+			Expect(func() { s.Write(newTestMessage(strings.Repeat("x", 200))) }).NotTo(Panic())
+		}
+		restore()
+
+		b, err := os.ReadFile(path.Join(dir, "app.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(string(b), strings.Repeat("x", 200))).To(Equal(5))
+		Expect(path.Join(dir, "app.1.log")).NotTo(BeAnExistingFile())
+
+		// Rotated on the next write
+		s.Write(newTestMessage("after"))
+		b, err = os.ReadFile(path.Join(dir, "app.1.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Count(string(b), strings.Repeat("x", 200))).To(Equal(5))
+
+		b, err = os.ReadFile(path.Join(dir, "app.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring("after"))
+	})
+
+	It("Test retention policy", func() {
+		dir := GinkgoT().TempDir()
+
+		// Prepare rotated files of the past days (one day apart)
+		for i := 1; i <= 5; i++ {
+			date := time.Now().AddDate(0, 0, -i)
+			name := path.Join(dir, "app."+date.Format("2006-01-02")+".log")
+
+			Expect(os.WriteFile(name, []byte("old\n"), 0644)).To(Succeed())
+			// An hour margin so the file of 2 days ago is still within MaxAgeDays
+			mtime := date.Add(time.Hour)
+			Expect(os.Chtimes(name, mtime, mtime)).To(Succeed())
+		}
+
+		// Files of other streams must never be touched
+		other := path.Join(dir, "other.2000-01-01.log")
+		Expect(os.WriteFile(other, []byte("old\n"), 0644)).To(Succeed())
+
+		s := stream.NewFileLogStream(stream.FileLogStreamOption{
+			LogDirectory: dir,
+			FileName:     "app",
+			FileRollover: true,
+			MaxBackups:   3,
+			MaxAgeDays:   2,
+		})
+		DeferCleanup(s.Close)
+		s.Write(newTestMessage("Hello, world!"))
+
+		day := func(i int) string {
+			return path.Join(dir, "app."+time.Now().AddDate(0, 0, -i).Format("2006-01-02")+".log")
+		}
+
+		// Only the files within 2 days are kept
+		Eventually(day(3)).ShouldNot(BeAnExistingFile())
+		Eventually(day(4)).ShouldNot(BeAnExistingFile())
+		Eventually(day(5)).ShouldNot(BeAnExistingFile())
+		Expect(day(1)).To(BeAnExistingFile())
+		Expect(day(2)).To(BeAnExistingFile())
+		Expect(day(0)).To(BeAnExistingFile())
+		Expect(other).To(BeAnExistingFile())
+	})
+
+	It("Test compression of rotated files", func() {
+		dir := GinkgoT().TempDir()
+
+		// Leftover of a compression interrupted by a restart
+		Expect(os.WriteFile(path.Join(dir, "app.1.log"), []byte("previous run\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(path.Join(dir, "app.1.log.gz.tmp"), []byte("garbage"), 0644)).To(Succeed())
+
+		s := stream.NewFileLogStream(stream.FileLogStreamOption{
+			LogDirectory:  dir,
+			FileName:      "app",
+			MaxFileSizeKb: 1,
+			Compress:      true,
+		})
+		DeferCleanup(s.Close)
+		for i := 0; i < 5; i++ {
+			s.Write(newTestMessage(strings.Repeat("x", 200)))
+		}
+
+		readGzip := func(name string) string {
+			f, err := os.Open(path.Join(dir, name))
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+
+			r, err := gzip.NewReader(f)
+			Expect(err).NotTo(HaveOccurred())
+
+			b, err := io.ReadAll(r)
+			Expect(err).NotTo(HaveOccurred())
+			return string(b)
+		}
+
+		// The rotated files are compressed and the originals removed
+		Eventually(path.Join(dir, "app.2.log")).ShouldNot(BeAnExistingFile())
+		Eventually(path.Join(dir, "app.1.log")).ShouldNot(BeAnExistingFile())
+		Expect(path.Join(dir, "app.1.log.gz.tmp")).NotTo(BeAnExistingFile())
+		Expect(readGzip("app.1.log.gz")).To(Equal("previous run\n"))
+		Expect(readGzip("app.2.log.gz")).To(ContainSubstring(strings.Repeat("x", 200)))
+
+		// The active file is never compressed
+		Expect(path.Join(dir, "app.log")).To(BeAnExistingFile())
+		Expect(path.Join(dir, "app.log.gz")).NotTo(BeAnExistingFile())
+	})
+
+	It("Test roll over while the maintenance is running", func() {
+		dir := GinkgoT().TempDir()
+		s := stream.NewFileLogStream(stream.FileLogStreamOption{
+			LogDirectory: dir,
+			FileName:     "app",
+			FileRollover: true,
+			Compress:     true,
+			MaxBackups:   1,
+		})
+
+		// The maintenance of the first file is still waiting when the next file becomes active
+		resume := s.PauseMaintenance()
+		s.CreateNewLog("2026-10-16")
+		s.CreateNewLog("2026-10-17")
+		s.CreateNewLog("2026-10-18")
+		resume()
+		Expect(s.Close()).To(Succeed())
+
+		// The active file is never compressed or pruned
+		Expect(path.Join(dir, "app.2026-10-18.log")).To(BeAnExistingFile())
+		Expect(path.Join(dir, "app.2026-10-18.log.gz")).NotTo(BeAnExistingFile())
+
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+	})
+
+	It("Test Close", func() {
+		dir := GinkgoT().TempDir()
+		s := stream.NewFileLogStream(stream.FileLogStreamOption{
+			LogDirectory: dir,
+			FileName:     "app",
+		})
+		s.Write(newTestMessage("before close"))
+
+		Expect(s.Flush()).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		// Writes after Close are dropped
+		s.Write(newTestMessage("after close"))
+
+		b, err := os.ReadFile(path.Join(dir, "app.log"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(ContainSubstring("before close"))
+		Expect(string(b)).NotTo(ContainSubstring("after close"))
+	})
+})
+
+func TestStream(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Stream Suite")
+}
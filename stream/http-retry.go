@@ -0,0 +1,178 @@
+package stream
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// Backoff of the retries of the requests to a remote service
+	retryOption struct {
+		maxRetries     int
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+	}
+
+	// Error of a request answered with a non 2xx status
+	HttpStatusError struct {
+		StatusCode int
+
+		// Start of the response body
+		Body string
+
+		// Delay requested by the Retry-After header. Zero if not set
+		retryAfter time.Duration
+	}
+)
+
+const (
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+
+	// Max length of the response body kept in the HttpStatusError
+	maxErrorBodyLength = 1024
+)
+
+func (e *HttpStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// Set the defaults of the unset values
+func newRetryOption(maxRetries int, initialBackoff, maxBackoff time.Duration) retryOption {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	if maxRetries < 0 {
+		// Negative disables the retries
+		maxRetries = 0
+	}
+	if initialBackoff <= 0 {
+		initialBackoff = defaultInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = initialBackoff
+	}
+
+	return retryOption{
+		maxRetries:     maxRetries,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+	}
+}
+
+// Get the delay of the Retry-After header. Either delay seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// Network errors, 408, 429 and 5xx responses can be retried
+func isRetryableError(err error) bool {
+	statusErr, ok := err.(*HttpStatusError)
+	if !ok {
+		return true
+	}
+
+	return statusErr.StatusCode == http.StatusRequestTimeout ||
+		statusErr.StatusCode == http.StatusTooManyRequests ||
+		statusErr.StatusCode >= 500
+}
+
+// Send the request. Non 2xx responses are returned as HttpStatusError
+func doRequest(client *http.Client, req *http.Request) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		// Drain the body so the connection can be reused
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyLength))
+	return &HttpStatusError{
+		StatusCode: res.StatusCode,
+		Body:       string(body),
+		retryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
+	}
+}
+
+// Send the request, retrying with an exponential backoff on the retryable errors.
+// The request is created for each attempt so the body can be read again.
+// The delay requested by Retry-After is used if longer than the backoff, even if longer than the max backoff.
+// Once closing is closed, the wait is cut short and no more retries are made so Close is not blocked
+func doWithRetry(client *http.Client, newRequest func() (*http.Request, error), retry retryOption, closing <-chan struct{}) error {
+	backoff := retry.initialBackoff
+
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return err
+		}
+
+		err = doRequest(client, req)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= retry.maxRetries || !isRetryableError(err) || isClosing(closing) {
+			return err
+		}
+
+		delay := backoff
+		if statusErr, ok := err.(*HttpStatusError); ok && statusErr.retryAfter > delay {
+			delay = statusErr.retryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-closing:
+			timer.Stop()
+			return err
+		}
+
+		backoff *= 2
+		if backoff > retry.maxBackoff {
+			backoff = retry.maxBackoff
+		}
+	}
+}
+
+func isClosing(closing <-chan struct{}) bool {
+	select {
+	case <-closing:
+		return true
+	default:
+		return false
+	}
+}
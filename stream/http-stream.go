@@ -0,0 +1,256 @@
+package stream
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/style"
+)
+
+type (
+	// Encoding of the batch in the request body
+	HttpEncoding int
+
+	HttpStreamOption struct {
+		// URL the batches are POSTed to
+		Url string
+
+		// Default is HttpNdjson
+		Encoding HttpEncoding
+
+		// If true, the body is compressed with gzip
+		Gzip bool
+
+		// Headers added to each request (e.g. authorization)
+		Headers map[string]string
+
+		// Max number of messages per request. Default is 500
+		BatchSize int
+
+		// Max size of the messages of a request (before compression). Default is 1MB
+		BatchBytes int
+
+		// Max delay before the queued messages are sent. Default is 1 second
+		FlushInterval time.Duration
+
+		// Max number of queued messages. Messages written to a full queue are dropped. Default is 8192
+		MaxQueueSize int
+
+		// Retries of a failed request. Default is 5, negative disables the retries
+		MaxRetries int
+
+		// Backoff before the first retry, doubled on each retry. Default is 500ms
+		RetryInitialBackoff time.Duration
+
+		// Max backoff between the retries. Retry-After is honoured even if longer. Default is 30 seconds
+		RetryMaxBackoff time.Duration
+
+		// Timeout of each request. Default is 10 seconds. Ignored if HttpClient is set
+		Timeout time.Duration
+
+		// Client used for the requests (e.g. for TLS settings)
+		HttpClient *http.Client
+
+		// Called with the batch abandoned after the retries (or rejected by the server) and the last error
+		OnDeadLetter func(batch []message.LogMessage, err error)
+	}
+
+	// POSTs the log messages in batches of JSON objects to an HTTP endpoint
+	HttpStream struct {
+		ILogStream
+
+		option  HttpStreamOption
+		client  *http.Client
+		retry   retryOption
+		batcher *batcher
+
+		mutex *sync.Mutex
+
+		// Messages dropped because the request failed
+		failed uint64
+
+		// Error of the last failed request. Reset by Flush
+		lastErr error
+	}
+)
+
+const (
+	// One JSON object per line (application/x-ndjson)
+	HttpNdjson HttpEncoding = iota
+
+	// JSON array of the objects (application/json)
+	HttpJsonArray
+)
+
+const (
+	defaultHttpBatchSize     = 500
+	defaultHttpBatchBytes    = 1 << 20
+	defaultHttpFlushInterval = time.Second
+	defaultHttpMaxQueueSize  = 8192
+	defaultHttpTimeout       = 10 * time.Second
+)
+
+// Get the request body of the batch and its content type
+func (s *HttpStream) body(batch []batchEntry) ([]byte, string) {
+	var buf bytes.Buffer
+
+	if s.option.Encoding == HttpJsonArray {
+		buf.WriteString("[")
+		for i, e := range batch {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.Write(bytes.TrimRight(e.data, "\n"))
+		}
+		buf.WriteString("]")
+		return buf.Bytes(), "application/json"
+	}
+
+	for _, e := range batch {
+		buf.Write(e.data)
+	}
+	return buf.Bytes(), "application/x-ndjson"
+}
+
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Send the batch. Failed batches are handed to the dead letter callback after the retries
+func (s *HttpStream) send(batch []batchEntry, closing <-chan struct{}) {
+	body, contentType := s.body(batch)
+
+	var err error
+	if s.option.Gzip {
+		body, err = gzipBody(body)
+	}
+
+	if err == nil {
+		err = doWithRetry(s.client, func() (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodPost, s.option.Url, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", contentType)
+			if s.option.Gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			for k, v := range s.option.Headers {
+				req.Header.Set(k, v)
+			}
+			return req, nil
+		}, s.retry, closing)
+	}
+
+	if err == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	s.failed += uint64(len(batch))
+	s.lastErr = err
+	s.mutex.Unlock()
+
+	if s.option.OnDeadLetter != nil {
+		msgs := make([]message.LogMessage, len(batch))
+		for i, e := range batch {
+			msgs[i] = e.msg
+		}
+		s.option.OnDeadLetter(msgs, err)
+	}
+}
+
+// Queue the message. The queued messages are sent in batches by a dedicated goroutine
+func (s *HttpStream) Write(msg message.LogMessage) {
+	line := style.GetMessageOfStyle(msg, style.JsonStyle)
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+
+	s.batcher.add(msg, []byte(line))
+}
+
+// Number of messages dropped because the queue was full, the request failed or they were written after Close
+func (s *HttpStream) Dropped() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.batcher.droppedCount() + s.failed
+}
+
+// Get the error of the last failed request and reset it
+func (s *HttpStream) takeLastError() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.lastErr
+	s.lastErr = nil
+	return err
+}
+
+// Send all queued messages. Returns the error of the last failed request since the previous Flush
+func (s *HttpStream) Flush() error {
+	s.batcher.flush()
+	return s.takeLastError()
+}
+
+// Send all queued messages and stop the sender goroutine. Writes after Close are dropped
+func (s *HttpStream) Close() error {
+	s.batcher.close()
+	return s.takeLastError()
+}
+
+func NewHttpStream(option HttpStreamOption) *HttpStream {
+	if option.Url == "" {
+		panic("NewHttpStream: Url is required")
+	}
+
+	if option.BatchSize <= 0 {
+		option.BatchSize = defaultHttpBatchSize
+	}
+	if option.BatchBytes <= 0 {
+		option.BatchBytes = defaultHttpBatchBytes
+	}
+	if option.FlushInterval <= 0 {
+		option.FlushInterval = defaultHttpFlushInterval
+	}
+	if option.MaxQueueSize <= 0 {
+		option.MaxQueueSize = defaultHttpMaxQueueSize
+	}
+	if option.MaxQueueSize < option.BatchSize {
+		option.MaxQueueSize = option.BatchSize
+	}
+	if option.Timeout <= 0 {
+		option.Timeout = defaultHttpTimeout
+	}
+
+	client := option.HttpClient
+	if client == nil {
+		client = &http.Client{Timeout: option.Timeout}
+	}
+
+	s := &HttpStream{
+		option: option,
+		client: client,
+		retry:  newRetryOption(option.MaxRetries, option.RetryInitialBackoff, option.RetryMaxBackoff),
+		mutex:  &sync.Mutex{},
+	}
+	s.batcher = newBatcher(option.BatchSize, option.BatchBytes, option.MaxQueueSize, option.FlushInterval, s.send)
+
+	return s
+}
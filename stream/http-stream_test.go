@@ -0,0 +1,220 @@
+package stream_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	"github.com/jhseong7/ecl/style"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Get the msg of each line of the NDJSON body
+func ndjsonMsgs(body []byte) []string {
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+		msgs = append(msgs, jsonLineMsg(line))
+	}
+	return msgs
+}
+
+var _ = Describe("HTTP Stream", func() {
+	var collector *TestCollector
+
+	BeforeEach(func() {
+		collector = newTestCollector()
+		DeferCleanup(collector.Close)
+	})
+
+	It("Test NDJSON with gzip and headers", func() {
+		s := stream.NewHttpStream(stream.HttpStreamOption{
+			Url:           collector.URL + "/logs",
+			Gzip:          true,
+			Headers:       map[string]string{"Authorization": "Bearer token"},
+			FlushInterval: time.Hour,
+		})
+
+		s.Write(newTestMessage("m0"))
+		s.Write(newTestMessage("m1"))
+		Expect(s.Close()).To(Succeed())
+
+		Expect(collector.Bodies()).To(HaveLen(1))
+		header := collector.Headers()[0]
+		Expect(header.Get("Content-Type")).To(Equal("application/x-ndjson"))
+		Expect(header.Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(header.Get("Authorization")).To(Equal("Bearer token"))
+
+		r, err := gzip.NewReader(bytes.NewReader(collector.Bodies()[0]))
+		Expect(err).NotTo(HaveOccurred())
+		body, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ndjsonMsgs(body)).To(Equal([]string{"m0", "m1"}))
+	})
+
+	It("Test JSON array", func() {
+		s := stream.NewHttpStream(stream.HttpStreamOption{
+			Url:           collector.URL,
+			Encoding:      stream.HttpJsonArray,
+			FlushInterval: time.Hour,
+		})
+
+		msg := newTestMessage("m0")
+		msg.Fields = []message.Field{message.Int("count", 3)}
+		s.Write(msg)
+		s.Write(newTestMessage("m1"))
+		Expect(s.Flush()).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+
+		Expect(collector.Headers()[0].Get("Content-Type")).To(Equal("application/json"))
+
+		var objects []map[string]interface{}
+		Expect(json.Unmarshal(collector.Bodies()[0], &objects)).To(Succeed())
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0]["msg"]).To(Equal("m0"))
+		Expect(objects[0]["count"]).To(BeEquivalentTo(3))
+		Expect(objects[1]["msg"]).To(Equal("m1"))
+	})
+
+	It("Test batching by count, bytes and interval", func() {
+		now := time.Now()
+		newMessage := func(msg string) message.LogMessage {
+			m := newTestMessage(msg)
+			m.Time = now
+			return m
+		}
+		lineLength := len(style.GetMessageOfStyle(newMessage("m0"), style.JsonStyle))
+
+		// 2 messages fit in a batch by the bytes
+		s := stream.NewHttpStream(stream.HttpStreamOption{
+			Url:           collector.URL,
+			BatchSize:     3,
+			BatchBytes:    lineLength * 2,
+			FlushInterval: time.Hour,
+		})
+		for _, m := range []string{"m0", "m1", "m2", "m3", "m4"} {
+			s.Write(newMessage(m))
+		}
+		Expect(s.Close()).To(Succeed())
+
+		var batches [][]string
+		for _, body := range collector.Bodies() {
+			batches = append(batches, ndjsonMsgs(body))
+		}
+		Expect(batches).To(Equal([][]string{{"m0", "m1"}, {"m2", "m3"}, {"m4"}}))
+
+		// A full batch by the count is sent without waiting for the interval
+		s = stream.NewHttpStream(stream.HttpStreamOption{
+			Url:           collector.URL,
+			BatchSize:     2,
+			FlushInterval: time.Hour,
+		})
+		s.Write(newMessage("m5"))
+		s.Write(newMessage("m6"))
+		Eventually(collector.Bodies).Should(HaveLen(4))
+		Expect(s.Close()).To(Succeed())
+
+		// Sent by the interval
+		s = stream.NewHttpStream(stream.HttpStreamOption{
+			Url:           collector.URL,
+			FlushInterval: 50 * time.Millisecond,
+		})
+		defer s.Close()
+
+		s.Write(newMessage("m7"))
+		Eventually(collector.Bodies).Should(HaveLen(5))
+	})
+
+	It("Test Retry-After and dead letter", func() {
+		var mutex sync.Mutex
+		var deadLetters [][]message.LogMessage
+		var deadLetterErr error
+
+		s := stream.NewHttpStream(stream.HttpStreamOption{
+			Url:                 collector.URL,
+			FlushInterval:       time.Hour,
+			MaxRetries:          1,
+			RetryInitialBackoff: 10 * time.Millisecond,
+			OnDeadLetter: func(batch []message.LogMessage, err error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				deadLetters = append(deadLetters, batch)
+				deadLetterErr = err
+			},
+		})
+		defer s.Close()
+
+		// The delay of Retry-After is used instead of the backoff
+		collector.retryAfter = "1"
+		collector.QueueStatuses(http.StatusTooManyRequests)
+
+		start := time.Now()
+		s.Write(newTestMessage("throttled"))
+		Expect(s.Flush()).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		Expect(collector.Bodies()).To(HaveLen(2))
+
+		// Abandoned after the retries
+		collector.retryAfter = ""
+		collector.QueueStatuses(http.StatusInternalServerError, http.StatusBadGateway)
+		s.Write(newTestMessage("abandoned"))
+
+		err := s.Flush()
+		Expect(err).To(HaveOccurred())
+		Expect(collector.Bodies()).To(HaveLen(4))
+		Expect(s.Dropped()).To(BeEquivalentTo(1))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(deadLetters).To(HaveLen(1))
+		Expect(deadLetters[0][0].Msg).To(Equal("abandoned"))
+		Expect(deadLetterErr.(*stream.HttpStatusError).StatusCode).To(Equal(http.StatusBadGateway))
+	})
+
+	It("Test Retry-After longer than the max backoff", func() {
+		s := stream.NewHttpStream(stream.HttpStreamOption{
+			Url:                 collector.URL,
+			FlushInterval:       time.Hour,
+			RetryInitialBackoff: 10 * time.Millisecond,
+			RetryMaxBackoff:     50 * time.Millisecond,
+		})
+		defer s.Close()
+
+		// The server is not retried before the requested delay
+		collector.retryAfter = "1"
+		collector.QueueStatuses(http.StatusServiceUnavailable)
+
+		start := time.Now()
+		s.Write(newTestMessage("throttled"))
+		Expect(s.Flush()).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+		Expect(collector.Bodies()).To(HaveLen(2))
+	})
+
+	It("Test Close stops the retries", func() {
+		s := stream.NewHttpStream(stream.HttpStreamOption{
+			Url:           collector.URL,
+			FlushInterval: 10 * time.Millisecond,
+		})
+
+		// Waits for an hour before the retry
+		collector.retryAfter = "3600"
+		collector.QueueStatuses(http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		s.Write(newTestMessage("throttled"))
+		Eventually(collector.Bodies).Should(HaveLen(1))
+
+		start := time.Now()
+		err := s.Close()
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		Expect(err.(*stream.HttpStatusError).StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(collector.Bodies()).To(HaveLen(1))
+		Expect(s.Dropped()).To(BeEquivalentTo(1))
+	})
+})
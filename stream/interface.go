@@ -5,8 +5,33 @@ import "github.com/jhseong7/ecl/message"
 type (
 	ILogStream interface {
 		Write(msg message.LogMessage)
+	}
+
+	// Optional interface for streams that buffer messages.
+	// The logger calls Flush so the stream can handle any pending messages before the program exits
+	IFlushableLogStream interface {
+		Flush() error
+	}
 
-		// TODO: add a flush method so the logger can handle any pending messages before the program exits
-		// Flush()
+	// Optional interface for streams that hold resources (files, connections, goroutines).
+	// Close must flush any pending messages before releasing the resources
+	IClosableLogStream interface {
+		Close() error
 	}
 )
+
+// Flush the stream if it implements IFlushableLogStream. Otherwise no-op
+func Flush(s ILogStream) error {
+	if f, ok := s.(IFlushableLogStream); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close the stream if it implements IClosableLogStream. Otherwise no-op
+func Close(s ILogStream) error {
+	if c, ok := s.(IClosableLogStream); ok {
+		return c.Close()
+	}
+	return nil
+}
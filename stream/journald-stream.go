@@ -0,0 +1,256 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+type (
+	JournaldStreamOption struct {
+		// Path of the journal socket. Default is /run/systemd/journal/socket
+		SocketPath string
+
+		// Prefix of the journal fields of the structured fields (e.g. "APP_"). Default is no prefix
+		FieldPrefix string
+	}
+
+	// Writes the log messages to the systemd journal with the native journal protocol,
+	// so the level and the fields are kept as journal fields (see journalctl -o verbose)
+	JournaldStream struct {
+		ILogStream
+
+		option JournaldStreamOption
+
+		conn *net.UnixConn
+
+		closed  bool
+		dropped uint64
+
+		mutex *sync.Mutex
+	}
+)
+
+const (
+	defaultJournaldSocketPath = "/run/systemd/journal/socket"
+
+	// Max length of a journal field name
+	journalMaxFieldNameLength = 64
+
+	// Prefix of the structured fields colliding with the journal fields set by the stream
+	journalCollidingFieldPrefix = "ECL_FIELD_"
+)
+
+// Journal fields set by the stream. Structured fields with these names are prefixed with journalCollidingFieldPrefix
+var journalReservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"ECL_APP":           true,
+	"ECL_LOGGER":        true,
+	"ECL_LEVEL":         true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"TRACE_ID":          true,
+	"SPAN_ID":           true,
+	"STACKTRACE":        true,
+}
+
+// Get the journal field name of the key. Only A-Z, 0-9 and _ are allowed, and the name must not start with _ or a digit.
+// Names of the journal fields set by the stream (e.g. MESSAGE) are prefixed so the entry has no duplicate fields
+func journalFieldName(prefix, key string) string {
+	b := make([]byte, 0, len(prefix)+len(key))
+	for _, c := range []byte(strings.ToUpper(prefix + key)) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b = append(b, c)
+		} else {
+			b = append(b, '_')
+		}
+	}
+
+	// Names starting with _ are the trusted fields set by journald
+	for len(b) > 0 && (b[0] == '_' || (b[0] >= '0' && b[0] <= '9')) {
+		b = b[1:]
+	}
+
+	name := string(b)
+	if journalReservedFields[name] {
+		name = journalCollidingFieldPrefix + name
+	}
+
+	if len(name) > journalMaxFieldNameLength {
+		name = name[:journalMaxFieldNameLength]
+	}
+	return name
+}
+
+// Append the field in the native journal protocol. Values with new lines are written with the binary length
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	if name == "" {
+		return
+	}
+
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteString("=")
+		buf.WriteString(value)
+		buf.WriteString("\n")
+		return
+	}
+
+	buf.WriteString("\n")
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteString("\n")
+}
+
+// Get the journal entry of the message
+func (s *JournaldStream) entry(msg message.LogMessage) []byte {
+	var buf bytes.Buffer
+
+	identifier := msg.Name
+	if identifier == "" {
+		identifier = msg.AppName
+	}
+
+	appendJournalField(&buf, "MESSAGE", msg.Msg)
+	appendJournalField(&buf, "PRIORITY", strconv.Itoa(syslogSeverity(msg.Level)))
+	if identifier != "" {
+		appendJournalField(&buf, "SYSLOG_IDENTIFIER", identifier)
+	}
+	appendJournalField(&buf, "ECL_APP", msg.AppName)
+	appendJournalField(&buf, "ECL_LOGGER", msg.Name)
+	appendJournalField(&buf, "ECL_LEVEL", msg.Level)
+
+	// Well-known fields of the caller
+	if msg.Caller.Defined() {
+		appendJournalField(&buf, "CODE_FILE", msg.Caller.File)
+		appendJournalField(&buf, "CODE_LINE", strconv.Itoa(msg.Caller.Line))
+		if msg.Caller.Function != "" {
+			appendJournalField(&buf, "CODE_FUNC", msg.Caller.Function)
+		}
+	}
+
+	if msg.TraceID != "" {
+		appendJournalField(&buf, "TRACE_ID", msg.TraceID)
+		appendJournalField(&buf, "SPAN_ID", msg.SpanID)
+	}
+
+	if msg.Stacktrace != "" {
+		appendJournalField(&buf, "STACKTRACE", msg.Stacktrace)
+	}
+
+	for _, f := range msg.Fields {
+		appendJournalField(&buf, journalFieldName(s.option.FieldPrefix, f.Key), f.ValueString())
+	}
+
+	return buf.Bytes()
+}
+
+func (s *JournaldStream) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: s.option.SocketPath, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	return nil
+}
+
+func (s *JournaldStream) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// Send the entry as a datagram. Entries too large for a datagram are sent as a sealed memfd
+func (s *JournaldStream) send(entry []byte) error {
+	if err := s.connect(); err != nil {
+		return err
+	}
+
+	_, err := s.conn.Write(entry)
+	if err != nil && isJournalEntryTooLarge(err) {
+		return sendJournalMemfd(s.conn, entry)
+	}
+	return err
+}
+
+// Write the message to the journal. If journald was restarted, the message is sent again on a new connection
+func (s *JournaldStream) Write(msg message.LogMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		s.dropped++
+		return
+	}
+
+	entry := s.entry(msg)
+	if err := s.send(entry); err != nil {
+		s.disconnect()
+		if err := s.send(entry); err != nil {
+			s.disconnect()
+			s.dropped++
+		}
+	}
+}
+
+// Number of messages dropped because the journal was not reachable or they were written after Close
+func (s *JournaldStream) Dropped() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.dropped
+}
+
+// Close the socket. Writes after Close are dropped
+func (s *JournaldStream) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func NewJournaldStream(options ...JournaldStreamOption) *JournaldStream {
+	// if len > 1, then it's an error
+	if len(options) > 1 {
+		panic("NewJournaldStream: Too many options")
+	}
+
+	var option JournaldStreamOption
+	if len(options) == 1 {
+		option = options[0]
+	}
+
+	if option.SocketPath == "" {
+		option.SocketPath = defaultJournaldSocketPath
+	}
+
+	return &JournaldStream{
+		option: option,
+		mutex:  &sync.Mutex{},
+	}
+}
@@ -0,0 +1,50 @@
+package stream
+
+import (
+	"errors"
+	"net"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// The datagram is larger than the socket buffer
+func isJournalEntryTooLarge(err error) bool {
+	return errors.Is(err, unix.EMSGSIZE) || errors.Is(err, unix.ENOBUFS)
+}
+
+// Write the entry to a sealed memfd and send its file descriptor instead, as journald expects for large entries
+func sendJournalMemfd(conn *net.UnixConn, entry []byte) error {
+	fd, err := unix.MemfdCreate("ecl-journal", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+
+	f := os.NewFile(uintptr(fd), "ecl-journal")
+	defer f.Close()
+
+	if _, err := f.Write(entry); err != nil {
+		return err
+	}
+
+	// journald only accepts sealed memfds so the entry cannot change after it is sent
+	if _, err := unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS, unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL); err != nil {
+		return err
+	}
+
+	// WriteMsgUnix refuses the connected datagram sockets, so sendmsg is called on the socket directly
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	err = rawConn.Write(func(sock uintptr) bool {
+		sendErr = unix.Sendmsg(int(sock), nil, unix.UnixRights(int(f.Fd())), nil, 0)
+		return sendErr != unix.EAGAIN
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
@@ -0,0 +1,54 @@
+package stream_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/sys/unix"
+)
+
+var _ = Describe("Journald Stream memfd", func() {
+	It("Test large entries are sent as a memfd", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "journal.sock")
+		server := listenTestJournalSocket(path)
+
+		s := stream.NewJournaldStream(stream.JournaldStreamOption{
+			SocketPath: path,
+		})
+		defer s.Close()
+
+		// Larger than the max datagram size of the socket
+		large := strings.Repeat("x", 4<<20)
+		s.Write(newTestMessage(large))
+		Expect(s.Dropped()).To(BeZero())
+
+		// Empty datagram carrying the file descriptor
+		oob := make([]byte, unix.CmsgSpace(4))
+		n, oobn, _, _, err := server.ReadMsgUnix(make([]byte, 1), oob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+
+		msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+		Expect(err).NotTo(HaveOccurred())
+		fds, err := unix.ParseUnixRights(&msgs[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fds).To(HaveLen(1))
+
+		f := os.NewFile(uintptr(fds[0]), "journal-entry")
+		defer f.Close()
+
+		// The memfd is sealed so it cannot be changed by the sender
+		seals, err := unix.FcntlInt(f.Fd(), unix.F_GET_SEALS, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(seals & unix.F_SEAL_WRITE).NotTo(BeZero())
+
+		entry, err := io.ReadAll(io.NewSectionReader(f, 0, 8<<20))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parseJournalEntry(entry)["MESSAGE"]).To(Equal([]string{large}))
+	})
+})
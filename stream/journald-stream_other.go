@@ -0,0 +1,17 @@
+//go:build !linux
+
+package stream
+
+import (
+	"errors"
+	"net"
+)
+
+// journald only runs on linux. Large entries are dropped on the other platforms
+func isJournalEntryTooLarge(err error) bool {
+	return false
+}
+
+func sendJournalMemfd(conn *net.UnixConn, entry []byte) error {
+	return errors.New("journald: memfd is not supported on this platform")
+}
@@ -0,0 +1,152 @@
+package stream_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Parse the entry of the native journal protocol
+func parseJournalEntry(b []byte) map[string][]string {
+	fields := map[string][]string{}
+	for len(b) > 0 {
+		i := bytes.IndexAny(b, "=\n")
+		Expect(i).To(BeNumerically(">", 0))
+		name := string(b[:i])
+
+		if b[i] == '=' {
+			end := bytes.IndexByte(b, '\n')
+			fields[name] = append(fields[name], string(b[i+1:end]))
+			b = b[end+1:]
+			continue
+		}
+
+		// Binary value: <name>\n<uint64 LE length><value>\n
+		b = b[i+1:]
+		n := binary.LittleEndian.Uint64(b[:8])
+		fields[name] = append(fields[name], string(b[8:8+n]))
+		Expect(b[8+n]).To(Equal(byte('\n')))
+		b = b[8+n+1:]
+	}
+	return fields
+}
+
+// Local unixgram socket standing in for the journal socket. The stale socket file of a previous listener is removed
+func listenTestJournalSocket(path string) *net.UnixConn {
+	os.Remove(path)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { conn.Close() })
+	return conn
+}
+
+var _ = Describe("Journald Stream", func() {
+	It("Test journal fields", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "journal.sock")
+		server := listenTestJournalSocket(path)
+
+		s := stream.NewJournaldStream(stream.JournaldStreamOption{
+			SocketPath: path,
+		})
+		defer s.Close()
+
+		msg := newTestMessage("hello")
+		msg.Level = "ERROR"
+		msg.Name = "OrderService"
+		msg.Caller = message.Caller{File: "main.go", Line: 42, Function: "main.main"}
+		msg.Stacktrace = "main.main()\n\tmain.go:42"
+		msg.Fields = []message.Field{
+			message.String("request.id", "abc"),
+			message.Int("_count", 3),
+			message.String("body", "multi\nline"),
+			message.String("message", "field"),
+			message.Int("priority", 1),
+		}
+		s.Write(msg)
+
+		buf := make([]byte, 65536)
+		n, err := server.Read(buf)
+		Expect(err).NotTo(HaveOccurred())
+
+		fields := parseJournalEntry(buf[:n])
+		Expect(fields).To(Equal(map[string][]string{
+			"MESSAGE":           {"hello"},
+			"PRIORITY":          {"3"},
+			"SYSLOG_IDENTIFIER": {"OrderService"},
+			"ECL_APP":           {"ECL"},
+			"ECL_LOGGER":        {"OrderService"},
+			"ECL_LEVEL":         {"ERROR"},
+			"CODE_FILE":         {"main.go"},
+			"CODE_LINE":         {"42"},
+			"CODE_FUNC":         {"main.main"},
+			"STACKTRACE":        {"main.main()\n\tmain.go:42"},
+			"REQUEST_ID":        {"abc"},
+			"COUNT":             {"3"},
+			"BODY":              {"multi\nline"},
+
+			// Not duplicating the fields set by the stream
+			"ECL_FIELD_MESSAGE":  {"field"},
+			"ECL_FIELD_PRIORITY": {"1"},
+		}))
+	})
+
+	It("Test field prefix and reconnect", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "journal.sock")
+		server := listenTestJournalSocket(path)
+
+		s := stream.NewJournaldStream(stream.JournaldStreamOption{
+			SocketPath:  path,
+			FieldPrefix: "app_",
+		})
+		defer s.Close()
+
+		msg := newTestMessage("hello")
+		msg.Level = "DEBUG"
+		msg.Name = ""
+		msg.Fields = []message.Field{message.String("user", "alice")}
+		s.Write(msg)
+
+		buf := make([]byte, 65536)
+		n, err := server.Read(buf)
+		Expect(err).NotTo(HaveOccurred())
+
+		fields := parseJournalEntry(buf[:n])
+		Expect(fields["APP_USER"]).To(Equal([]string{"alice"}))
+		Expect(fields["PRIORITY"]).To(Equal([]string{"7"}))
+
+		// The app name is the identifier of the unnamed loggers
+		Expect(fields["SYSLOG_IDENTIFIER"]).To(Equal([]string{"ECL"}))
+
+		// No identifier without the logger name and the app name
+		msg.AppName = ""
+		s.Write(msg)
+		n, err = server.Read(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parseJournalEntry(buf[:n])).NotTo(HaveKey("SYSLOG_IDENTIFIER"))
+
+		// Restart of journald. The socket is created again at the same path
+		server.Close()
+		s.Write(newTestMessage("lost"))
+		Expect(s.Dropped()).To(BeEquivalentTo(1))
+
+		server = listenTestJournalSocket(path)
+		s.Write(newTestMessage("again"))
+
+		n, err = server.Read(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parseJournalEntry(buf[:n])["MESSAGE"]).To(Equal([]string{"again"}))
+		Expect(s.Dropped()).To(BeEquivalentTo(1))
+
+		// Writes after Close are dropped
+		Expect(s.Close()).To(Succeed())
+		s.Write(newTestMessage("closed"))
+		Expect(s.Dropped()).To(BeEquivalentTo(2))
+	})
+})
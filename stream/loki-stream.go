@@ -0,0 +1,407 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/style"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type (
+	// Encoding of the push request
+	LokiEncoding int
+
+	// Names of the labels taken from the messages. An empty name doesn't add the label
+	LokiLabelNames struct {
+		App    string
+		Logger string
+		Level  string
+	}
+
+	LokiStreamOption struct {
+		// URL of the push API. Default is http://localhost:3100/loki/api/v1/push
+		Url string
+
+		// Default is LokiJson
+		Encoding LokiEncoding
+
+		// Labels taken from the app name, the logger name and the level. Default is DefaultLokiLabelNames
+		LabelNames *LokiLabelNames
+
+		// Labels added to all streams (e.g. env, host)
+		StaticLabels map[string]string
+
+		// Keys of the fields promoted to labels. Use only the low cardinality fields, the other fields stay in the log line
+		FieldLabels []string
+
+		// Style of the log lines, without the terminal styles. Default is JSON
+		LogStyle style.LogStyle
+
+		// Tenant of a multi-tenant Loki (X-Scope-OrgID header)
+		TenantID string
+
+		// Headers added to each request (e.g. authorization)
+		Headers map[string]string
+
+		// Max number of log lines per request. Default is 1000
+		BatchSize int
+
+		// Max size of the log lines of a request. Default is 1MB
+		BatchBytes int
+
+		// Max delay before the queued lines are pushed. Default is 1 second
+		FlushInterval time.Duration
+
+		// Max number of queued lines. Lines written to a full queue are dropped. Default is 8192
+		MaxQueueSize int
+
+		// Retries of a failed push. Default is 5, negative disables the retries
+		MaxRetries int
+
+		// Backoff before the first retry, doubled on each retry. Default is 500ms
+		RetryInitialBackoff time.Duration
+
+		// Max backoff between the retries. Retry-After is honoured even if longer. Default is 30 seconds
+		RetryMaxBackoff time.Duration
+
+		// Timeout of each request. Default is 10 seconds. Ignored if HttpClient is set
+		Timeout time.Duration
+
+		// Client used for the requests (e.g. for TLS settings)
+		HttpClient *http.Client
+	}
+
+	// Pushes the log messages to Grafana Loki, grouped into streams by their labels
+	LokiStream struct {
+		ILogStream
+
+		option  LokiStreamOption
+		client  *http.Client
+		retry   retryOption
+		batcher *batcher
+
+		mutex *sync.Mutex
+
+		// Lines dropped because the push failed
+		failed uint64
+
+		// Error of the last failed push. Reset by Flush
+		lastErr error
+	}
+
+	// Log lines of a label set
+	lokiStream struct {
+		labels map[string]string
+
+		// Label set in the Prometheus format ({key="value", ...}, sorted by key)
+		key string
+
+		entries []batchEntry
+	}
+)
+
+const (
+	// JSON push request (application/json)
+	LokiJson LokiEncoding = iota
+
+	// Snappy compressed protobuf push request (application/x-protobuf)
+	LokiProtobuf
+)
+
+const (
+	defaultLokiUrl           = "http://localhost:3100/loki/api/v1/push"
+	defaultLokiBatchSize     = 1000
+	defaultLokiBatchBytes    = 1 << 20
+	defaultLokiFlushInterval = time.Second
+	defaultLokiMaxQueueSize  = 8192
+	defaultLokiTimeout       = 10 * time.Second
+)
+
+// Labels of the app name, the logger name and the level
+var DefaultLokiLabelNames = LokiLabelNames{
+	App:    "app",
+	Logger: "logger",
+	Level:  "level",
+}
+
+// Get the valid label name. Only [a-zA-Z0-9_] are allowed, and the name must not start with a digit
+func lokiLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || (c >= '0' && c <= '9' && i > 0)) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// Get the labels of the message. Labels with an empty value are left out as Loki rejects them
+func (s *LokiStream) labels(msg message.LogMessage) map[string]string {
+	labels := map[string]string{}
+	for k, v := range s.option.StaticLabels {
+		labels[lokiLabelName(k)] = v
+	}
+
+	names := s.option.LabelNames
+	if names.App != "" {
+		labels[lokiLabelName(names.App)] = msg.AppName
+	}
+	if names.Logger != "" {
+		labels[lokiLabelName(names.Logger)] = msg.Name
+	}
+	if names.Level != "" {
+		labels[lokiLabelName(names.Level)] = strings.ToLower(msg.Level)
+	}
+
+	for _, key := range s.option.FieldLabels {
+		for _, f := range msg.Fields {
+			if f.Key == key {
+				labels[lokiLabelName(key)] = f.ValueString()
+			}
+		}
+	}
+
+	for k, v := range labels {
+		if v == "" {
+			delete(labels, k)
+		}
+	}
+
+	return labels
+}
+
+// Get the label set in the Prometheus format ({key="value", ...}, sorted by key)
+func lokiLabelString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(labels[k]))
+	}
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+// Group the lines into streams by the labels. The lines of a stream are ordered by time
+func (s *LokiStream) group(batch []batchEntry) []*lokiStream {
+	var streams []*lokiStream
+	index := map[string]*lokiStream{}
+
+	for _, e := range batch {
+		labels := s.labels(e.msg)
+		key := lokiLabelString(labels)
+
+		stream, ok := index[key]
+		if !ok {
+			stream = &lokiStream{labels: labels, key: key}
+			index[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.entries = append(stream.entries, e)
+	}
+
+	for _, stream := range streams {
+		entries := stream.entries
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].msg.Time.Before(entries[j].msg.Time)
+		})
+	}
+
+	return streams
+}
+
+// Encode the JSON push request
+func encodeLokiJson(streams []*lokiStream) ([]byte, error) {
+	list := make([]interface{}, 0, len(streams))
+
+	for _, stream := range streams {
+		values := make([][2]string, 0, len(stream.entries))
+		for _, e := range stream.entries {
+			values = append(values, [2]string{
+				strconv.FormatInt(e.msg.Time.UnixNano(), 10),
+				strings.TrimRight(string(e.data), "\n"),
+			})
+		}
+
+		list = append(list, map[string]interface{}{
+			"stream": stream.labels,
+			"values": values,
+		})
+	}
+
+	return json.Marshal(map[string]interface{}{"streams": list})
+}
+
+// Encode the PushRequest protobuf, compressed with snappy
+func encodeLokiProtobuf(streams []*lokiStream) []byte {
+	var req []byte
+
+	for _, stream := range streams {
+		var sb []byte
+		sb = appendProtoString(sb, 1, stream.key)
+
+		for _, e := range stream.entries {
+			var ts []byte
+			ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(e.msg.Time.Unix()))
+			ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+			ts = protowire.AppendVarint(ts, uint64(e.msg.Time.Nanosecond()))
+
+			var entry []byte
+			entry = appendProtoMessage(entry, 1, ts)
+			entry = appendProtoString(entry, 2, strings.TrimRight(string(e.data), "\n"))
+
+			sb = appendProtoMessage(sb, 2, entry)
+		}
+
+		req = appendProtoMessage(req, 1, sb)
+	}
+
+	return snappy.Encode(nil, req)
+}
+
+// Push the batch. Failed batches are dropped after the retries
+func (s *LokiStream) push(batch []batchEntry, closing <-chan struct{}) {
+	streams := s.group(batch)
+
+	var body []byte
+	var contentType string
+	var err error
+
+	if s.option.Encoding == LokiProtobuf {
+		contentType = "application/x-protobuf"
+		body = encodeLokiProtobuf(streams)
+	} else {
+		contentType = "application/json"
+		body, err = encodeLokiJson(streams)
+	}
+
+	if err == nil {
+		err = doWithRetry(s.client, func() (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodPost, s.option.Url, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", contentType)
+			if s.option.Encoding == LokiProtobuf {
+				req.Header.Set("Content-Encoding", "snappy")
+			}
+			if s.option.TenantID != "" {
+				req.Header.Set("X-Scope-OrgID", s.option.TenantID)
+			}
+			for k, v := range s.option.Headers {
+				req.Header.Set(k, v)
+			}
+			return req, nil
+		}, s.retry, closing)
+	}
+
+	if err != nil {
+		s.mutex.Lock()
+		s.failed += uint64(len(batch))
+		s.lastErr = err
+		s.mutex.Unlock()
+	}
+}
+
+// Queue the message without the terminal styles. The queued messages are pushed in batches by a dedicated goroutine
+func (s *LokiStream) Write(msg message.LogMessage) {
+	line := style.StripTerminalStyle(style.GetMessageOfStyle(msg, s.option.LogStyle))
+	s.batcher.add(msg, []byte(line))
+}
+
+// Number of messages dropped because the queue was full, the push failed or they were written after Close
+func (s *LokiStream) Dropped() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.batcher.droppedCount() + s.failed
+}
+
+// Get the error of the last failed push and reset it
+func (s *LokiStream) takeLastError() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.lastErr
+	s.lastErr = nil
+	return err
+}
+
+// Push all queued messages. Returns the error of the last failed push since the previous Flush
+func (s *LokiStream) Flush() error {
+	s.batcher.flush()
+	return s.takeLastError()
+}
+
+// Push all queued messages and stop the pusher goroutine. Writes after Close are dropped
+func (s *LokiStream) Close() error {
+	s.batcher.close()
+	return s.takeLastError()
+}
+
+func NewLokiStream(option LokiStreamOption) *LokiStream {
+	if option.Url == "" {
+		option.Url = defaultLokiUrl
+	}
+	if option.LabelNames == nil {
+		names := DefaultLokiLabelNames
+		option.LabelNames = &names
+	}
+	if option.LogStyle == "" {
+		option.LogStyle = style.JsonStyle
+	}
+	if option.BatchSize <= 0 {
+		option.BatchSize = defaultLokiBatchSize
+	}
+	if option.BatchBytes <= 0 {
+		option.BatchBytes = defaultLokiBatchBytes
+	}
+	if option.FlushInterval <= 0 {
+		option.FlushInterval = defaultLokiFlushInterval
+	}
+	if option.MaxQueueSize <= 0 {
+		option.MaxQueueSize = defaultLokiMaxQueueSize
+	}
+	if option.MaxQueueSize < option.BatchSize {
+		option.MaxQueueSize = option.BatchSize
+	}
+	if option.Timeout <= 0 {
+		option.Timeout = defaultLokiTimeout
+	}
+
+	client := option.HttpClient
+	if client == nil {
+		client = &http.Client{Timeout: option.Timeout}
+	}
+
+	s := &LokiStream{
+		option: option,
+		client: client,
+		retry:  newRetryOption(option.MaxRetries, option.RetryInitialBackoff, option.RetryMaxBackoff),
+		mutex:  &sync.Mutex{},
+	}
+	s.batcher = newBatcher(option.BatchSize, option.BatchBytes, option.MaxQueueSize, option.FlushInterval, s.push)
+
+	return s
+}
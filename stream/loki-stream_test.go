@@ -0,0 +1,152 @@
+package stream_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	"github.com/jhseong7/ecl/style"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type lokiPushRequest struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+var _ = Describe("Loki Stream", func() {
+	var collector *TestCollector
+
+	BeforeEach(func() {
+		collector = newTestCollector()
+		DeferCleanup(collector.Close)
+	})
+
+	It("Test streams grouped by labels", func() {
+		s := stream.NewLokiStream(stream.LokiStreamOption{
+			Url:           collector.URL + "/loki/api/v1/push",
+			StaticLabels:  map[string]string{"env": "test"},
+			TenantID:      "team-a",
+			FlushInterval: time.Hour,
+		})
+
+		first := newTestMessage("m0")
+		first.Fields = []message.Field{message.String("request_id", "abc")}
+		s.Write(first)
+
+		other := newTestMessage("m1")
+		other.Level = "ERROR"
+		s.Write(other)
+
+		s.Write(newTestMessage("m2"))
+		Expect(s.Close()).To(Succeed())
+
+		Expect(collector.Bodies()).To(HaveLen(1))
+		header := collector.Headers()[0]
+		Expect(header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(header.Get("X-Scope-OrgID")).To(Equal("team-a"))
+
+		var req lokiPushRequest
+		Expect(json.Unmarshal(collector.Bodies()[0], &req)).To(Succeed())
+		Expect(req.Streams).To(HaveLen(2))
+
+		// The fields stay in the log line
+		Expect(req.Streams[0].Stream).To(Equal(map[string]string{"app": "ECL", "logger": "test", "level": "info", "env": "test"}))
+		Expect(req.Streams[0].Values).To(HaveLen(2))
+		Expect(req.Streams[0].Values[0][0]).To(Equal(strconv.FormatInt(first.Time.UnixNano(), 10)))
+		Expect(jsonLineMsg(req.Streams[0].Values[0][1])).To(Equal("m0"))
+		Expect(req.Streams[0].Values[0][1]).To(ContainSubstring(`"request_id":"abc"`))
+		Expect(jsonLineMsg(req.Streams[0].Values[1][1])).To(Equal("m2"))
+
+		Expect(req.Streams[1].Stream["level"]).To(Equal("error"))
+		Expect(jsonLineMsg(req.Streams[1].Values[0][1])).To(Equal("m1"))
+	})
+
+	It("Test label names and field labels", func() {
+		s := stream.NewLokiStream(stream.LokiStreamOption{
+			Url:           collector.URL,
+			LabelNames:    &stream.LokiLabelNames{App: "service_name"},
+			FieldLabels:   []string{"http.method"},
+			LogStyle:      style.LogfmtStyle,
+			FlushInterval: time.Hour,
+		})
+
+		msg := newTestMessage("m0")
+		msg.Fields = []message.Field{message.String("http.method", "GET"), message.String("user", "alice")}
+		s.Write(msg)
+		Expect(s.Close()).To(Succeed())
+
+		var req lokiPushRequest
+		Expect(json.Unmarshal(collector.Bodies()[0], &req)).To(Succeed())
+		Expect(req.Streams).To(HaveLen(1))
+		Expect(req.Streams[0].Stream).To(Equal(map[string]string{"service_name": "ECL", "http_method": "GET"}))
+		Expect(req.Streams[0].Values[0][1]).To(HavePrefix("time="))
+		Expect(req.Streams[0].Values[0][1]).To(HaveSuffix("user=alice"))
+	})
+
+	It("Test text style without terminal styles", func() {
+		s := stream.NewLokiStream(stream.LokiStreamOption{
+			Url:           collector.URL,
+			LogStyle:      style.SpringStyle,
+			FlushInterval: time.Hour,
+		})
+
+		msg := newTestMessage("hello")
+		msg.Color = style.Green
+		s.Write(msg)
+		Expect(s.Close()).To(Succeed())
+
+		var req lokiPushRequest
+		Expect(json.Unmarshal(collector.Bodies()[0], &req)).To(Succeed())
+		line := req.Streams[0].Values[0][1]
+		Expect(line).To(ContainSubstring("hello"))
+		Expect(line).NotTo(ContainSubstring("\x1b"))
+	})
+
+	It("Test snappy protobuf and retry", func() {
+		s := stream.NewLokiStream(stream.LokiStreamOption{
+			Url:                 collector.URL,
+			Encoding:            stream.LokiProtobuf,
+			FlushInterval:       time.Hour,
+			RetryInitialBackoff: 10 * time.Millisecond,
+		})
+		defer s.Close()
+
+		collector.QueueStatuses(http.StatusServiceUnavailable)
+
+		msg := newTestMessage("hello")
+		s.Write(msg)
+		Expect(s.Flush()).To(Succeed())
+		Expect(s.Dropped()).To(BeZero())
+
+		Expect(collector.Bodies()).To(HaveLen(2))
+		header := collector.Headers()[1]
+		Expect(header.Get("Content-Type")).To(Equal("application/x-protobuf"))
+		Expect(header.Get("Content-Encoding")).To(Equal("snappy"))
+
+		body, err := snappy.Decode(nil, collector.Bodies()[1])
+		Expect(err).NotTo(HaveOccurred())
+
+		// PushRequest.streams -> StreamAdapter.labels, StreamAdapter.entries -> EntryAdapter
+		streams := protoFieldValues(body, 1)
+		Expect(streams).To(HaveLen(1))
+		Expect(protoFieldValues(streams[0].([]byte), 1)).To(Equal([]interface{}{[]byte(`{app="ECL", level="info", logger="test"}`)}))
+
+		entries := protoFieldValues(streams[0].([]byte), 2)
+		Expect(entries).To(HaveLen(1))
+
+		timestamp := protoFieldValues(entries[0].([]byte), 1)[0].([]byte)
+		Expect(protoFieldValues(timestamp, 1)).To(Equal([]interface{}{uint64(msg.Time.Unix())}))
+		Expect(protoFieldValues(timestamp, 2)).To(Equal([]interface{}{uint64(msg.Time.Nanosecond())}))
+
+		line := protoFieldValues(entries[0].([]byte), 2)[0].([]byte)
+		Expect(jsonLineMsg(string(line))).To(Equal("hello"))
+	})
+})
@@ -0,0 +1,335 @@
+package stream
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/style"
+)
+
+type (
+	// Framing of the messages on the connection
+	NetworkFraming int
+
+	NetworkStreamOption struct {
+		// "tcp", "udp", "unix" or "unixgram"
+		Network string
+
+		// host:port, or the socket path for unix
+		Address string
+
+		// Style of the messages. Default is JSON
+		LogStyle style.LogStyle
+
+		// Default is NewlineFraming
+		Framing NetworkFraming
+
+		// If set, the TCP connection is secured with TLS
+		TLSConfig *tls.Config
+
+		// Timeout of the connection and each write. Default is 5 seconds
+		Timeout time.Duration
+
+		// Delay before the first reconnection attempt, doubled on each failed attempt. Default is 500ms
+		ReconnectInitialBackoff time.Duration
+
+		// Default is 30 seconds
+		ReconnectMaxBackoff time.Duration
+
+		// Max number of messages kept while disconnected. The oldest are dropped first. Default is 1024, negative disables the buffer
+		BufferSize int
+	}
+
+	NetworkStreamStats struct {
+		// Messages written to the connection
+		Sent uint64
+
+		// Messages dropped because the buffer was full or they were written after Close
+		Dropped uint64
+
+		// Messages waiting for the connection
+		Buffered int
+
+		// Successful reconnections after the connection dropped
+		Reconnects uint64
+	}
+
+	// Sends the messages in the log style to a TCP, UDP or unix socket.
+	// The messages are buffered while disconnected and sent once reconnected
+	NetworkStream struct {
+		ILogStream
+
+		option NetworkStreamOption
+
+		conn net.Conn
+
+		// Frames waiting for the connection
+		buffer [][]byte
+
+		// True while the reconnect goroutine is running
+		reconnecting bool
+		connected    bool
+
+		closed bool
+		stats  NetworkStreamStats
+
+		mutex *sync.Mutex
+		stop  chan struct{}
+		wg    *sync.WaitGroup
+	}
+)
+
+const (
+	// Each message ends with a new line. New lines in the message (e.g. a stack trace block) are escaped as \n
+	NewlineFraming NetworkFraming = iota
+
+	// Each message is prefixed with its length as a 4 byte big endian integer
+	LengthPrefixFraming
+)
+
+const (
+	defaultNetworkTimeout          = 5 * time.Second
+	defaultNetworkInitialBackoff   = 500 * time.Millisecond
+	defaultNetworkMaxBackoff       = 30 * time.Second
+	defaultNetworkStreamBufferSize = 1024
+)
+
+// Get the framed message without the terminal styles
+func (s *NetworkStream) frame(msg message.LogMessage) []byte {
+	line := style.StripTerminalStyle(style.GetMessageOfStyle(msg, s.option.LogStyle))
+	line = strings.TrimRight(line, "\n")
+
+	if s.option.Framing == LengthPrefixFraming {
+		frame := make([]byte, 4, 4+len(line))
+		binary.BigEndian.PutUint32(frame, uint32(len(line)))
+		return append(frame, line...)
+	}
+
+	// Keep the multi-line messages in a single frame
+	return []byte(strings.ReplaceAll(line, "\n", `\n`) + "\n")
+}
+
+func (s *NetworkStream) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: s.option.Timeout}
+	if s.option.TLSConfig != nil {
+		return tls.DialWithDialer(dialer, s.option.Network, s.option.Address, s.option.TLSConfig)
+	}
+	return dialer.Dial(s.option.Network, s.option.Address)
+}
+
+func (s *NetworkStream) write(frame []byte) error {
+	s.conn.SetWriteDeadline(time.Now().Add(s.option.Timeout))
+	if _, err := s.conn.Write(frame); err != nil {
+		return err
+	}
+
+	s.stats.Sent++
+	return nil
+}
+
+// Write the buffered frames in order. The frame that failed is kept in the buffer
+func (s *NetworkStream) drain() error {
+	for len(s.buffer) > 0 {
+		if err := s.write(s.buffer[0]); err != nil {
+			return err
+		}
+		s.buffer[0] = nil
+		s.buffer = s.buffer[1:]
+	}
+	return nil
+}
+
+// Keep the frame until reconnected. The oldest frame is dropped if the buffer is full
+func (s *NetworkStream) bufferFrame(frame []byte) {
+	if s.option.BufferSize < 0 {
+		s.stats.Dropped++
+		return
+	}
+
+	if len(s.buffer) >= s.option.BufferSize {
+		s.buffer[0] = nil
+		s.buffer = s.buffer[1:]
+		s.stats.Dropped++
+	}
+	s.buffer = append(s.buffer, frame)
+}
+
+func (s *NetworkStream) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// Start the reconnect goroutine if not running
+func (s *NetworkStream) startReconnect() {
+	if s.reconnecting || s.closed {
+		return
+	}
+
+	s.reconnecting = true
+	s.wg.Add(1)
+	go s.reconnect()
+}
+
+// Connect with an exponential backoff until connected and the buffer is sent, or the stream is closed
+func (s *NetworkStream) reconnect() {
+	defer s.wg.Done()
+
+	backoff := s.option.ReconnectInitialBackoff
+
+	for {
+		conn, err := s.dial()
+
+		if err == nil {
+			s.mutex.Lock()
+			if s.closed {
+				s.mutex.Unlock()
+				conn.Close()
+				return
+			}
+
+			s.conn = conn
+			if s.connected {
+				s.stats.Reconnects++
+			}
+			s.connected = true
+
+			if s.drain() == nil {
+				s.reconnecting = false
+				s.mutex.Unlock()
+				return
+			}
+			s.disconnect()
+			s.mutex.Unlock()
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > s.option.ReconnectMaxBackoff {
+			backoff = s.option.ReconnectMaxBackoff
+		}
+	}
+}
+
+// Send the message. While disconnected, the message is buffered and sent once reconnected
+func (s *NetworkStream) Write(msg message.LogMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		s.stats.Dropped++
+		return
+	}
+
+	frame := s.frame(msg)
+
+	if s.conn != nil {
+		if err := s.write(frame); err == nil {
+			return
+		}
+
+		// The connection dropped
+		s.disconnect()
+		s.startReconnect()
+	}
+
+	s.bufferFrame(frame)
+}
+
+func (s *NetworkStream) Stats() NetworkStreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.stats
+	stats.Buffered = len(s.buffer)
+	return stats
+}
+
+// Number of messages dropped because the buffer was full or they were written after Close
+func (s *NetworkStream) Dropped() uint64 {
+	return s.Stats().Dropped
+}
+
+// The messages are written without buffering while connected. Returns an error if messages are waiting for the connection
+func (s *NetworkStream) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.buffer) > 0 {
+		return fmt.Errorf("network stream: %d messages waiting for the connection to %s", len(s.buffer), s.option.Address)
+	}
+	return nil
+}
+
+// Stop reconnecting and close the connection. The messages still buffered and the writes after Close are dropped
+func (s *NetworkStream) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.stop)
+
+	s.stats.Dropped += uint64(len(s.buffer))
+	s.buffer = nil
+
+	var err error
+	if s.conn != nil {
+		err = s.conn.Close()
+		s.conn = nil
+	}
+	s.mutex.Unlock()
+
+	// Wait for the reconnect goroutine
+	s.wg.Wait()
+
+	return err
+}
+
+// Create the stream. The connection is opened in the background, and the messages written before are buffered
+func NewNetworkStream(option NetworkStreamOption) *NetworkStream {
+	if option.LogStyle == "" {
+		option.LogStyle = style.JsonStyle
+	}
+	if option.Timeout <= 0 {
+		option.Timeout = defaultNetworkTimeout
+	}
+	if option.ReconnectInitialBackoff <= 0 {
+		option.ReconnectInitialBackoff = defaultNetworkInitialBackoff
+	}
+	if option.ReconnectMaxBackoff <= 0 {
+		option.ReconnectMaxBackoff = defaultNetworkMaxBackoff
+	}
+	if option.ReconnectMaxBackoff < option.ReconnectInitialBackoff {
+		option.ReconnectMaxBackoff = option.ReconnectInitialBackoff
+	}
+	if option.BufferSize == 0 {
+		option.BufferSize = defaultNetworkStreamBufferSize
+	}
+
+	s := &NetworkStream{
+		option: option,
+		mutex:  &sync.Mutex{},
+		stop:   make(chan struct{}),
+		wg:     &sync.WaitGroup{},
+	}
+
+	s.mutex.Lock()
+	s.startReconnect()
+	s.mutex.Unlock()
+
+	return s
+}
@@ -0,0 +1,240 @@
+package stream_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/jhseong7/ecl/stream"
+	"github.com/jhseong7/ecl/style"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Accept the connections and send the lines read from them to the channel
+func serveLines(listener net.Listener, lines chan<- string, conns chan<- net.Conn) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		if conns != nil {
+			conns <- conn
+		}
+		go func() {
+			r := bufio.NewReader(conn)
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				lines <- line
+			}
+		}()
+	}
+}
+
+// Get the msg of the JSON style line
+func jsonLineMsg(line string) string {
+	var v map[string]interface{}
+	Expect(json.Unmarshal([]byte(line), &v)).To(Succeed())
+	return v["msg"].(string)
+}
+
+// Self-signed certificate of 127.0.0.1
+func newTestCertificate() (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ecl-test"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+var _ = Describe("Network Stream", func() {
+	It("Test newline framing over TCP", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		lines := make(chan string, 100)
+		go serveLines(listener, lines, nil)
+
+		s := stream.NewNetworkStream(stream.NetworkStreamOption{
+			Network: "tcp",
+			Address: listener.Addr().String(),
+		})
+		defer s.Close()
+
+		// JSON by default, one message per line
+		s.Write(newTestMessage("m0"))
+		s.Write(newTestMessage("m1"))
+
+		Eventually(lines).Should(Receive(WithTransform(jsonLineMsg, Equal("m0"))))
+		Eventually(lines).Should(Receive(WithTransform(jsonLineMsg, Equal("m1"))))
+		Eventually(func() uint64 { return s.Stats().Sent }).Should(BeEquivalentTo(2))
+		Expect(s.Flush()).To(Succeed())
+	})
+
+	It("Test newline framing of a multi-line text style", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		lines := make(chan string, 100)
+		go serveLines(listener, lines, nil)
+
+		s := stream.NewNetworkStream(stream.NetworkStreamOption{
+			Network:  "tcp",
+			Address:  listener.Addr().String(),
+			LogStyle: style.DefaultStyle,
+		})
+		defer s.Close()
+
+		msg := newTestMessage("failed")
+		msg.Color = style.Red
+		msg.Stacktrace = "main.main()\n\tmain.go:42"
+		s.Write(msg)
+		s.Write(newTestMessage("next"))
+
+		// The stack trace block stays in the frame of the message, without the terminal styles
+		var line string
+		Eventually(lines).Should(Receive(&line))
+		Expect(line).NotTo(ContainSubstring("\x1b"))
+		Expect(line).To(ContainSubstring("failed"))
+		Expect(line).To(ContainSubstring(`\n    main.main()\n`))
+		Eventually(lines).Should(Receive(ContainSubstring("next")))
+	})
+
+	It("Test length prefix framing over unix socket", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "log.sock")
+		listener, err := net.Listen("unix", path)
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		s := stream.NewNetworkStream(stream.NetworkStreamOption{
+			Network:  "unix",
+			Address:  path,
+			LogStyle: style.LogfmtStyle,
+			Framing:  stream.LengthPrefixFraming,
+		})
+		defer s.Close()
+
+		s.Write(newTestMessage("hello"))
+
+		conn, err := listener.Accept()
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		var length uint32
+		Expect(binary.Read(conn, binary.BigEndian, &length)).To(Succeed())
+		payload := make([]byte, length)
+		_, err = io.ReadFull(conn, payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(payload)).To(HavePrefix("time="))
+		Expect(string(payload)).To(HaveSuffix("msg=hello"))
+	})
+
+	It("Test buffering and reconnect", func() {
+		// Reserve an address with no listener
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address := listener.Addr().String()
+		listener.Close()
+
+		s := stream.NewNetworkStream(stream.NetworkStreamOption{
+			Network:                 "tcp",
+			Address:                 address,
+			ReconnectInitialBackoff: 10 * time.Millisecond,
+			ReconnectMaxBackoff:     50 * time.Millisecond,
+			BufferSize:              2,
+		})
+		defer s.Close()
+
+		// The oldest message is dropped when the buffer is full
+		s.Write(newTestMessage("m0"))
+		s.Write(newTestMessage("m1"))
+		s.Write(newTestMessage("m2"))
+
+		stats := s.Stats()
+		Expect(stats.Buffered).To(Equal(2))
+		Expect(stats.Dropped).To(BeEquivalentTo(1))
+		Expect(s.Flush()).To(HaveOccurred())
+
+		// The buffered messages are sent once the server is up
+		listener, err = net.Listen("tcp", address)
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		lines := make(chan string, 100)
+		conns := make(chan net.Conn, 10)
+		go serveLines(listener, lines, conns)
+
+		Eventually(lines, 5*time.Second).Should(Receive(WithTransform(jsonLineMsg, Equal("m1"))))
+		Eventually(lines).Should(Receive(WithTransform(jsonLineMsg, Equal("m2"))))
+		Expect(s.Flush()).To(Succeed())
+
+		// Drop the connection from the server side. Writes fail after a while, then the stream reconnects
+		(<-conns).Close()
+		Eventually(func() int {
+			s.Write(newTestMessage("after"))
+			return len(conns)
+		}, 5*time.Second, 20*time.Millisecond).Should(Equal(1))
+
+		Eventually(func() uint64 { return s.Stats().Reconnects }).Should(BeEquivalentTo(1))
+		Eventually(lines).Should(Receive(WithTransform(jsonLineMsg, Equal("after"))))
+
+		// Writes after Close are dropped
+		Expect(s.Close()).To(Succeed())
+		dropped := s.Dropped()
+		s.Write(newTestMessage("closed"))
+		Expect(s.Dropped()).To(Equal(dropped + 1))
+	})
+
+	It("Test TLS", func() {
+		cert, pool := newTestCertificate()
+		listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		lines := make(chan string, 100)
+		go serveLines(listener, lines, nil)
+
+		s := stream.NewNetworkStream(stream.NetworkStreamOption{
+			Network:   "tcp",
+			Address:   listener.Addr().String(),
+			TLSConfig: &tls.Config{RootCAs: pool},
+		})
+		defer s.Close()
+
+		s.Write(newTestMessage("secure"))
+		Eventually(lines, 5*time.Second).Should(Receive(WithTransform(jsonLineMsg, Equal("secure"))))
+	})
+})
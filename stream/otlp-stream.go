@@ -0,0 +1,488 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type (
+	// Encoding of the OTLP/HTTP request body
+	OtlpEncoding int
+
+	OtlpLogStreamOption struct {
+		// URL of the OTLP/HTTP logs endpoint. Default is http://localhost:4318/v1/logs
+		Endpoint string
+
+		// Default is OtlpProtobuf
+		Encoding OtlpEncoding
+
+		// Headers added to each request (e.g. authorization)
+		Headers map[string]string
+
+		// Attributes added to the resource of the logs. service.name is set to the app name unless set here
+		ResourceAttributes map[string]string
+
+		// Max number of log records per request. Default is 512
+		BatchSize int
+
+		// Max delay before the queued records are exported. Default is 1 second
+		FlushInterval time.Duration
+
+		// Max number of queued records. Records written to a full queue are dropped. Default is 8192
+		MaxQueueSize int
+
+		// Retries of a failed export. Default is 5, negative disables the retries
+		MaxRetries int
+
+		// Backoff before the first retry, doubled on each retry. Default is 500ms
+		RetryInitialBackoff time.Duration
+
+		// Max backoff between the retries. Retry-After is honoured even if longer. Default is 30 seconds
+		RetryMaxBackoff time.Duration
+
+		// Timeout of each request. Default is 10 seconds. Ignored if HttpClient is set
+		Timeout time.Duration
+
+		// Client used for the requests (e.g. for TLS settings)
+		HttpClient *http.Client
+	}
+
+	// Exports the log messages as OTLP log records to an OpenTelemetry collector over OTLP/HTTP
+	OtlpLogStream struct {
+		ILogStream
+
+		option  OtlpLogStreamOption
+		client  *http.Client
+		retry   retryOption
+		batcher *batcher
+
+		mutex *sync.Mutex
+
+		// Records dropped because the export failed
+		failed uint64
+
+		// Error of the last failed export. Reset by Flush
+		lastErr error
+	}
+
+	// Log records of a logger
+	otlpScopeLogs struct {
+		name    string
+		records []message.LogMessage
+	}
+
+	// Log records of an app
+	otlpResourceLogs struct {
+		appName string
+		scopes  []*otlpScopeLogs
+	}
+)
+
+const (
+	OtlpProtobuf OtlpEncoding = iota
+	OtlpJson
+)
+
+const (
+	defaultOtlpEndpoint      = "http://localhost:4318/v1/logs"
+	defaultOtlpBatchSize     = 512
+	defaultOtlpFlushInterval = time.Second
+	defaultOtlpMaxQueueSize  = 8192
+	defaultOtlpTimeout       = 10 * time.Second
+)
+
+// Get the OpenTelemetry severity number of the level. 0 (unspecified) for unknown levels
+func otlpSeverityNumber(level string) int32 {
+	switch level {
+	case "TRACE":
+		return 1
+	case "DEBUG":
+		return 5
+	case "INFO", "LOG":
+		return 9
+	case "WARN":
+		return 13
+	case "ERROR":
+		return 17
+	case "FATAL", "PANIC":
+		return 21
+	default:
+		return 0
+	}
+}
+
+// Group the messages by app (resource) and logger name (scope), keeping the order of the first appearance
+func groupOtlpLogs(batch []message.LogMessage) []*otlpResourceLogs {
+	var resources []*otlpResourceLogs
+	resourceIndex := map[string]*otlpResourceLogs{}
+	scopeIndex := map[[2]string]*otlpScopeLogs{}
+
+	for _, msg := range batch {
+		resource, ok := resourceIndex[msg.AppName]
+		if !ok {
+			resource = &otlpResourceLogs{appName: msg.AppName}
+			resourceIndex[msg.AppName] = resource
+			resources = append(resources, resource)
+		}
+
+		key := [2]string{msg.AppName, msg.Name}
+		scope, ok := scopeIndex[key]
+		if !ok {
+			scope = &otlpScopeLogs{name: msg.Name}
+			scopeIndex[key] = scope
+			resource.scopes = append(resource.scopes, scope)
+		}
+
+		scope.records = append(scope.records, msg)
+	}
+
+	return resources
+}
+
+// Get the attributes of the resource, sorted by key
+func (s *OtlpLogStream) resourceAttributes(appName string) []message.Field {
+	var attrs []message.Field
+	if _, ok := s.option.ResourceAttributes["service.name"]; !ok {
+		attrs = append(attrs, message.String("service.name", appName))
+	}
+
+	keys := make([]string, 0, len(s.option.ResourceAttributes))
+	for k := range s.option.ResourceAttributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		attrs = append(attrs, message.String(k, s.option.ResourceAttributes[k]))
+	}
+
+	return attrs
+}
+
+// Get the attributes of the log record. The fields, followed by the caller and the stack trace (semantic conventions)
+func otlpRecordAttributes(msg message.LogMessage) []message.Field {
+	attrs := append([]message.Field{}, msg.Fields...)
+
+	if msg.Caller.Defined() {
+		attrs = append(attrs,
+			message.String("code.filepath", msg.Caller.File),
+			message.Int("code.lineno", msg.Caller.Line),
+		)
+		if msg.Caller.Function != "" {
+			attrs = append(attrs, message.String("code.function", msg.Caller.Function))
+		}
+	}
+
+	if msg.Stacktrace != "" {
+		attrs = append(attrs, message.String("code.stacktrace", msg.Stacktrace))
+	}
+
+	return attrs
+}
+
+// Decode the hex trace or span ID. Nil if the ID is not valid
+func decodeOtlpId(id string, size int) []byte {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != size {
+		return nil
+	}
+	return b
+}
+
+// Get the OTLP/JSON AnyValue of the field. Int64 values are strings as in the protobuf JSON mapping
+func otlpJsonValue(f message.Field) map[string]interface{} {
+	switch f.Type {
+	case message.IntType:
+		if _, ok := f.Value.(int64); ok {
+			return map[string]interface{}{"intValue": f.ValueString()}
+		}
+	case message.FloatType:
+		// NaN and Inf are not valid JSON numbers
+		if v, ok := f.Value.(float64); ok && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return map[string]interface{}{"doubleValue": v}
+		}
+	case message.BoolType:
+		if v, ok := f.Value.(bool); ok {
+			return map[string]interface{}{"boolValue": v}
+		}
+	}
+
+	return map[string]interface{}{"stringValue": f.ValueString()}
+}
+
+func otlpJsonAttributes(attrs []message.Field) []interface{} {
+	list := make([]interface{}, 0, len(attrs))
+	for _, f := range attrs {
+		list = append(list, map[string]interface{}{
+			"key":   f.Key,
+			"value": otlpJsonValue(f),
+		})
+	}
+	return list
+}
+
+// Encode the ExportLogsServiceRequest in the OTLP/JSON encoding
+func (s *OtlpLogStream) encodeJson(resources []*otlpResourceLogs, observed time.Time) ([]byte, error) {
+	resourceLogs := make([]interface{}, 0, len(resources))
+
+	for _, resource := range resources {
+		scopeLogs := make([]interface{}, 0, len(resource.scopes))
+
+		for _, scope := range resource.scopes {
+			records := make([]interface{}, 0, len(scope.records))
+
+			for _, msg := range scope.records {
+				record := map[string]interface{}{
+					"timeUnixNano":         strconv.FormatInt(msg.Time.UnixNano(), 10),
+					"observedTimeUnixNano": strconv.FormatInt(observed.UnixNano(), 10),
+					"severityNumber":       otlpSeverityNumber(msg.Level),
+					"severityText":         msg.Level,
+					"body":                 map[string]interface{}{"stringValue": msg.Msg},
+					"attributes":           otlpJsonAttributes(otlpRecordAttributes(msg)),
+				}
+
+				// The IDs are hex strings in OTLP/JSON
+				if decodeOtlpId(msg.TraceID, 16) != nil && decodeOtlpId(msg.SpanID, 8) != nil {
+					record["traceId"] = msg.TraceID
+					record["spanId"] = msg.SpanID
+				}
+
+				records = append(records, record)
+			}
+
+			scopeLogs = append(scopeLogs, map[string]interface{}{
+				"scope":      map[string]interface{}{"name": scope.name},
+				"logRecords": records,
+			})
+		}
+
+		resourceLogs = append(resourceLogs, map[string]interface{}{
+			"resource":  map[string]interface{}{"attributes": otlpJsonAttributes(s.resourceAttributes(resource.appName))},
+			"scopeLogs": scopeLogs,
+		})
+	}
+
+	return json.Marshal(map[string]interface{}{"resourceLogs": resourceLogs})
+}
+
+// Append the embedded message field
+func appendProtoMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendProtoString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// Encode the AnyValue of the field
+func otlpProtoValue(f message.Field) []byte {
+	var b []byte
+
+	switch v := f.Value.(type) {
+	case int64:
+		if f.Type == message.IntType {
+			b = protowire.AppendTag(b, 3, protowire.VarintType)
+			return protowire.AppendVarint(b, uint64(v))
+		}
+	case float64:
+		if f.Type == message.FloatType {
+			b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+			return protowire.AppendFixed64(b, math.Float64bits(v))
+		}
+	case bool:
+		if f.Type == message.BoolType {
+			b = protowire.AppendTag(b, 2, protowire.VarintType)
+			return protowire.AppendVarint(b, protowire.EncodeBool(v))
+		}
+	}
+
+	// Other types and the values not matching the type (e.g. a hand-built Field)
+	return appendProtoString(b, 1, f.ValueString())
+}
+
+// Append the repeated KeyValue attributes
+func appendOtlpProtoAttributes(b []byte, num protowire.Number, attrs []message.Field) []byte {
+	for _, f := range attrs {
+		var kv []byte
+		kv = appendProtoString(kv, 1, f.Key)
+		kv = appendProtoMessage(kv, 2, otlpProtoValue(f))
+		b = appendProtoMessage(b, num, kv)
+	}
+	return b
+}
+
+// Encode the LogRecord
+func otlpProtoRecord(msg message.LogMessage, observed time.Time) []byte {
+	var b []byte
+
+	b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(msg.Time.UnixNano()))
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(otlpSeverityNumber(msg.Level)))
+	b = appendProtoString(b, 3, msg.Level)
+	b = appendProtoMessage(b, 5, appendProtoString(nil, 1, msg.Msg))
+	b = appendOtlpProtoAttributes(b, 6, otlpRecordAttributes(msg))
+
+	traceId, spanId := decodeOtlpId(msg.TraceID, 16), decodeOtlpId(msg.SpanID, 8)
+	if traceId != nil && spanId != nil {
+		b = protowire.AppendTag(b, 9, protowire.BytesType)
+		b = protowire.AppendBytes(b, traceId)
+		b = protowire.AppendTag(b, 10, protowire.BytesType)
+		b = protowire.AppendBytes(b, spanId)
+	}
+
+	b = protowire.AppendTag(b, 11, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(observed.UnixNano()))
+
+	return b
+}
+
+// Encode the ExportLogsServiceRequest in the OTLP protobuf encoding
+func (s *OtlpLogStream) encodeProtobuf(resources []*otlpResourceLogs, observed time.Time) []byte {
+	var req []byte
+
+	for _, resource := range resources {
+		var resourceLogs []byte
+		resourceLogs = appendProtoMessage(resourceLogs, 1, appendOtlpProtoAttributes(nil, 1, s.resourceAttributes(resource.appName)))
+
+		for _, scope := range resource.scopes {
+			var scopeLogs []byte
+			scopeLogs = appendProtoMessage(scopeLogs, 1, appendProtoString(nil, 1, scope.name))
+			for _, msg := range scope.records {
+				scopeLogs = appendProtoMessage(scopeLogs, 2, otlpProtoRecord(msg, observed))
+			}
+			resourceLogs = appendProtoMessage(resourceLogs, 2, scopeLogs)
+		}
+
+		req = appendProtoMessage(req, 1, resourceLogs)
+	}
+
+	return req
+}
+
+// Export the batch. Failed batches are dropped after the retries
+func (s *OtlpLogStream) export(entries []batchEntry, closing <-chan struct{}) {
+	batch := make([]message.LogMessage, len(entries))
+	for i, e := range entries {
+		batch[i] = e.msg
+	}
+
+	resources := groupOtlpLogs(batch)
+	observed := time.Now()
+
+	var body []byte
+	var contentType string
+	var err error
+
+	if s.option.Encoding == OtlpJson {
+		contentType = "application/json"
+		body, err = s.encodeJson(resources, observed)
+	} else {
+		contentType = "application/x-protobuf"
+		body = s.encodeProtobuf(resources, observed)
+	}
+
+	if err == nil {
+		err = doWithRetry(s.client, func() (*http.Request, error) {
+			req, err := http.NewRequest(http.MethodPost, s.option.Endpoint, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", contentType)
+			for k, v := range s.option.Headers {
+				req.Header.Set(k, v)
+			}
+			return req, nil
+		}, s.retry, closing)
+	}
+
+	if err != nil {
+		s.mutex.Lock()
+		s.failed += uint64(len(batch))
+		s.lastErr = err
+		s.mutex.Unlock()
+	}
+}
+
+// Queue the message. The queued messages are exported in batches by a dedicated goroutine
+func (s *OtlpLogStream) Write(msg message.LogMessage) {
+	s.batcher.add(msg, nil)
+}
+
+// Number of messages dropped because the queue was full, the export failed or they were written after Close
+func (s *OtlpLogStream) Dropped() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.batcher.droppedCount() + s.failed
+}
+
+// Get the error of the last failed export and reset it
+func (s *OtlpLogStream) takeLastError() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.lastErr
+	s.lastErr = nil
+	return err
+}
+
+// Export all queued messages. Returns the error of the last failed export since the previous Flush
+func (s *OtlpLogStream) Flush() error {
+	s.batcher.flush()
+	return s.takeLastError()
+}
+
+// Export all queued messages and stop the exporter goroutine. Writes after Close are dropped
+func (s *OtlpLogStream) Close() error {
+	s.batcher.close()
+	return s.takeLastError()
+}
+
+func NewOtlpLogStream(option OtlpLogStreamOption) *OtlpLogStream {
+	if option.Endpoint == "" {
+		option.Endpoint = defaultOtlpEndpoint
+	}
+	if option.BatchSize <= 0 {
+		option.BatchSize = defaultOtlpBatchSize
+	}
+	if option.FlushInterval <= 0 {
+		option.FlushInterval = defaultOtlpFlushInterval
+	}
+	if option.MaxQueueSize <= 0 {
+		option.MaxQueueSize = defaultOtlpMaxQueueSize
+	}
+	if option.MaxQueueSize < option.BatchSize {
+		option.MaxQueueSize = option.BatchSize
+	}
+	if option.Timeout <= 0 {
+		option.Timeout = defaultOtlpTimeout
+	}
+
+	client := option.HttpClient
+	if client == nil {
+		client = &http.Client{Timeout: option.Timeout}
+	}
+
+	s := &OtlpLogStream{
+		option: option,
+		client: client,
+		retry:  newRetryOption(option.MaxRetries, option.RetryInitialBackoff, option.RetryMaxBackoff),
+		mutex:  &sync.Mutex{},
+	}
+	s.batcher = newBatcher(option.BatchSize, 0, option.MaxQueueSize, option.FlushInterval, s.export)
+
+	return s
+}
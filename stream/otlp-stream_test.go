@@ -0,0 +1,314 @@
+package stream_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Stand-in of a log collector that records the request bodies and answers with the queued statuses (200 when empty)
+type TestCollector struct {
+	*httptest.Server
+
+	mutex       sync.Mutex
+	bodies      [][]byte
+	headers     []http.Header
+	statuses    []int
+	retryAfter  string
+	contentType string
+}
+
+func newTestCollector() *TestCollector {
+	c := &TestCollector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		c.bodies = append(c.bodies, body)
+		c.headers = append(c.headers, r.Header.Clone())
+
+		status := http.StatusOK
+		if len(c.statuses) > 0 {
+			status = c.statuses[0]
+			c.statuses = c.statuses[1:]
+		}
+		if status != http.StatusOK && c.retryAfter != "" {
+			w.Header().Set("Retry-After", c.retryAfter)
+		}
+		w.WriteHeader(status)
+	}))
+	return c
+}
+
+func (c *TestCollector) Bodies() [][]byte {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([][]byte{}, c.bodies...)
+}
+
+func (c *TestCollector) Headers() []http.Header {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]http.Header{}, c.headers...)
+}
+
+func (c *TestCollector) QueueStatuses(statuses ...int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.statuses = append(c.statuses, statuses...)
+}
+
+type otlpJsonRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []map[string]interface{} `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			LogRecords []map[string]interface{} `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+// Count the log records of an OTLP/JSON request
+func countOtlpJsonRecords(body []byte) int {
+	var req otlpJsonRequest
+	Expect(json.Unmarshal(body, &req)).To(Succeed())
+
+	n := 0
+	for _, rl := range req.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			n += len(sl.LogRecords)
+		}
+	}
+	return n
+}
+
+// Get the values of the field number in the protobuf message
+func protoFieldValues(b []byte, num protowire.Number) []interface{} {
+	var values []interface{}
+	for len(b) > 0 {
+		n, typ, l := protowire.ConsumeTag(b)
+		Expect(l).To(BeNumerically(">", 0))
+		b = b[l:]
+
+		var v interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, l = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			v, l = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			v, l = protowire.ConsumeBytes(b)
+		default:
+			Fail("unexpected wire type")
+		}
+		Expect(l).To(BeNumerically(">", 0))
+		b = b[l:]
+
+		if n == num {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+var _ = Describe("OTLP Log Stream", func() {
+	var collector *TestCollector
+
+	BeforeEach(func() {
+		collector = newTestCollector()
+		DeferCleanup(collector.Close)
+	})
+
+	It("Test JSON encoding", func() {
+		s := stream.NewOtlpLogStream(stream.OtlpLogStreamOption{
+			Endpoint:           collector.URL + "/v1/logs",
+			Encoding:           stream.OtlpJson,
+			Headers:            map[string]string{"Authorization": "Bearer token"},
+			ResourceAttributes: map[string]string{"deployment.environment": "test"},
+			FlushInterval:      time.Hour,
+		})
+
+		msg := newTestMessage("hello")
+		msg.Level = "WARN"
+		msg.Fields = []message.Field{message.String("user", "alice"), message.Int("count", 3), message.Bool("ok", true)}
+		msg.TraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		msg.SpanID = "00f067aa0ba902b7"
+		msg.Caller = message.Caller{File: "main.go", Line: 42, Function: "main.main"}
+		s.Write(msg)
+
+		other := newTestMessage("world")
+		other.Name = "other"
+		other.Level = "ERROR"
+		s.Write(other)
+
+		Expect(s.Flush()).To(Succeed())
+
+		bodies := collector.Bodies()
+		Expect(bodies).To(HaveLen(1))
+		Expect(collector.Headers()[0].Get("Content-Type")).To(Equal("application/json"))
+		Expect(collector.Headers()[0].Get("Authorization")).To(Equal("Bearer token"))
+
+		var req otlpJsonRequest
+		Expect(json.Unmarshal(bodies[0], &req)).To(Succeed())
+		Expect(req.ResourceLogs).To(HaveLen(1))
+
+		rl := req.ResourceLogs[0]
+		Expect(rl.Resource.Attributes).To(ContainElement(map[string]interface{}{
+			"key": "service.name", "value": map[string]interface{}{"stringValue": "ECL"},
+		}))
+		Expect(rl.Resource.Attributes).To(ContainElement(map[string]interface{}{
+			"key": "deployment.environment", "value": map[string]interface{}{"stringValue": "test"},
+		}))
+
+		// One scope per logger name
+		Expect(rl.ScopeLogs).To(HaveLen(2))
+		Expect(rl.ScopeLogs[0].Scope.Name).To(Equal("test"))
+		Expect(rl.ScopeLogs[1].Scope.Name).To(Equal("other"))
+
+		record := rl.ScopeLogs[0].LogRecords[0]
+		Expect(record["severityNumber"]).To(BeEquivalentTo(13))
+		Expect(record["severityText"]).To(Equal("WARN"))
+		Expect(record["body"]).To(Equal(map[string]interface{}{"stringValue": "hello"}))
+		Expect(record["timeUnixNano"]).To(BeAssignableToTypeOf(""))
+		Expect(record["traceId"]).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+		Expect(record["spanId"]).To(Equal("00f067aa0ba902b7"))
+		Expect(record["attributes"]).To(ContainElements(
+			map[string]interface{}{"key": "user", "value": map[string]interface{}{"stringValue": "alice"}},
+			map[string]interface{}{"key": "count", "value": map[string]interface{}{"intValue": "3"}},
+			map[string]interface{}{"key": "ok", "value": map[string]interface{}{"boolValue": true}},
+			map[string]interface{}{"key": "code.lineno", "value": map[string]interface{}{"intValue": "42"}},
+		))
+
+		Expect(rl.ScopeLogs[1].LogRecords[0]["severityNumber"]).To(BeEquivalentTo(17))
+		Expect(s.Close()).To(Succeed())
+	})
+
+	It("Test protobuf encoding", func() {
+		s := stream.NewOtlpLogStream(stream.OtlpLogStreamOption{
+			Endpoint:      collector.URL + "/v1/logs",
+			FlushInterval: time.Hour,
+		})
+
+		msg := newTestMessage("hello")
+		msg.Level = "DEBUG"
+		s.Write(msg)
+		Expect(s.Close()).To(Succeed())
+
+		Expect(collector.Bodies()).To(HaveLen(1))
+		Expect(collector.Headers()[0].Get("Content-Type")).To(Equal("application/x-protobuf"))
+
+		// ExportLogsServiceRequest.resource_logs -> ResourceLogs.scope_logs -> ScopeLogs.log_records
+		resourceLogs := protoFieldValues(collector.Bodies()[0], 1)
+		Expect(resourceLogs).To(HaveLen(1))
+		scopeLogs := protoFieldValues(resourceLogs[0].([]byte), 2)
+		Expect(scopeLogs).To(HaveLen(1))
+
+		scope := protoFieldValues(scopeLogs[0].([]byte), 1)
+		Expect(protoFieldValues(scope[0].([]byte), 1)).To(Equal([]interface{}{[]byte("test")}))
+
+		records := protoFieldValues(scopeLogs[0].([]byte), 2)
+		Expect(records).To(HaveLen(1))
+
+		record := records[0].([]byte)
+		Expect(protoFieldValues(record, 1)).To(Equal([]interface{}{uint64(msg.Time.UnixNano())}))
+		Expect(protoFieldValues(record, 2)).To(Equal([]interface{}{uint64(5)}))
+		Expect(protoFieldValues(record, 3)).To(Equal([]interface{}{[]byte("DEBUG")}))
+
+		body := protoFieldValues(record, 5)
+		Expect(protoFieldValues(body[0].([]byte), 1)).To(Equal([]interface{}{[]byte("hello")}))
+	})
+
+	It("Test batching by size and interval", func() {
+		s := stream.NewOtlpLogStream(stream.OtlpLogStreamOption{
+			Endpoint:      collector.URL,
+			Encoding:      stream.OtlpJson,
+			BatchSize:     2,
+			FlushInterval: time.Hour,
+		})
+
+		// A full batch is exported without waiting for the interval
+		s.Write(newTestMessage("m0"))
+		s.Write(newTestMessage("m1"))
+		Eventually(collector.Bodies).Should(HaveLen(1))
+
+		s.Write(newTestMessage("m2"))
+		s.Write(newTestMessage("m3"))
+		s.Write(newTestMessage("m4"))
+		Expect(s.Close()).To(Succeed())
+
+		counts := []int{}
+		for _, body := range collector.Bodies() {
+			counts = append(counts, countOtlpJsonRecords(body))
+		}
+		Expect(counts).To(Equal([]int{2, 2, 1}))
+
+		// Writes after Close are dropped
+		s.Write(newTestMessage("m5"))
+		Expect(s.Dropped()).To(BeEquivalentTo(1))
+
+		s = stream.NewOtlpLogStream(stream.OtlpLogStreamOption{
+			Endpoint:      collector.URL,
+			Encoding:      stream.OtlpJson,
+			FlushInterval: 50 * time.Millisecond,
+		})
+		defer s.Close()
+
+		s.Write(newTestMessage("m6"))
+		Eventually(collector.Bodies).Should(HaveLen(4))
+	})
+
+	It("Test retry with backoff", func() {
+		s := stream.NewOtlpLogStream(stream.OtlpLogStreamOption{
+			Endpoint:            collector.URL,
+			FlushInterval:       time.Hour,
+			RetryInitialBackoff: 10 * time.Millisecond,
+		})
+		defer s.Close()
+
+		// Retryable statuses
+		collector.QueueStatuses(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		s.Write(newTestMessage("retried"))
+		Expect(s.Flush()).To(Succeed())
+		Expect(collector.Bodies()).To(HaveLen(3))
+		Expect(s.Dropped()).To(BeZero())
+
+		// Client errors are not retried and the batch is dropped
+		collector.QueueStatuses(http.StatusBadRequest)
+		s.Write(newTestMessage("rejected"))
+
+		err := s.Flush()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*stream.HttpStatusError).StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(collector.Bodies()).To(HaveLen(4))
+		Expect(s.Dropped()).To(BeEquivalentTo(1))
+
+		// Gives up after the max retries
+		s2 := stream.NewOtlpLogStream(stream.OtlpLogStreamOption{
+			Endpoint:            collector.URL,
+			FlushInterval:       time.Hour,
+			MaxRetries:          2,
+			RetryInitialBackoff: 10 * time.Millisecond,
+		})
+		collector.QueueStatuses(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+		s2.Write(newTestMessage("failed"))
+		Expect(s2.Flush()).To(HaveOccurred())
+		Expect(collector.Bodies()).To(HaveLen(7))
+		Expect(s2.Dropped()).To(BeEquivalentTo(1))
+		Expect(s2.Close()).To(Succeed())
+	})
+})
@@ -47,6 +47,14 @@ func NewStdOutStream(options ...StdOutStreamOption) *StdOutStream {
 		return &StdOutStream{
 			logStyle: style.SpringStyle,
 		}
+	case string(style.JsonStyle):
+		return &StdOutStream{
+			logStyle: style.JsonStyle,
+		}
+	case string(style.LogfmtStyle):
+		return &StdOutStream{
+			logStyle: style.LogfmtStyle,
+		}
 	}
 
 	return &StdOutStream{}
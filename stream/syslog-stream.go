@@ -0,0 +1,335 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/style"
+)
+
+type (
+	// Format of the syslog messages
+	SyslogFormat int
+
+	// Facility of the syslog messages
+	SyslogFacility int
+
+	SyslogStreamOption struct {
+		// "unix", "unixgram", "udp" or "tcp". Empty connects to the local syslog daemon (/dev/log)
+		Network string
+
+		// Address of the syslog server (host:port, or the socket path for unix). Ignored for the local syslog daemon
+		Address string
+
+		// Default is SyslogRFC5424
+		Format SyslogFormat
+
+		// Default is SyslogUser
+		Facility SyslogFacility
+
+		// HOSTNAME of the messages. Default is the host name of the machine
+		Hostname string
+
+		// If set, the MSG is the log in the style. Default is the message followed by the fields (key=value)
+		LogStyle style.LogStyle
+
+		// Timeout of the connection and each write. Default is 5 seconds
+		Timeout time.Duration
+
+		// Min delay before connecting again after a failed connection. Messages written in between are dropped. Default is 1 second
+		ReconnectInterval time.Duration
+	}
+
+	// Writes the log messages to a syslog daemon or server.
+	// The connection is opened on the first write and opened again when it drops
+	SyslogStream struct {
+		ILogStream
+
+		option   SyslogStreamOption
+		hostname string
+		pid      int
+
+		conn net.Conn
+
+		// Don't connect before this time after a failed connection
+		nextDial time.Time
+
+		closed  bool
+		dropped uint64
+
+		mutex *sync.Mutex
+	}
+)
+
+const (
+	// RFC 5424 format. <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID - MSG
+	SyslogRFC5424 SyslogFormat = iota
+
+	// Legacy BSD format (RFC 3164). <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
+	SyslogRFC3164
+)
+
+const (
+	SyslogUser   SyslogFacility = 1
+	SyslogMail   SyslogFacility = 2
+	SyslogDaemon SyslogFacility = 3
+	SyslogAuth   SyslogFacility = 4
+	SyslogLocal0 SyslogFacility = 16
+	SyslogLocal1 SyslogFacility = 17
+	SyslogLocal2 SyslogFacility = 18
+	SyslogLocal3 SyslogFacility = 19
+	SyslogLocal4 SyslogFacility = 20
+	SyslogLocal5 SyslogFacility = 21
+	SyslogLocal6 SyslogFacility = 22
+	SyslogLocal7 SyslogFacility = 23
+)
+
+const (
+	defaultSyslogTimeout           = 5 * time.Second
+	defaultSyslogReconnectInterval = time.Second
+
+	// Max lengths of the header fields (RFC 5424 6.2, RFC 3164 4.1.3)
+	syslogMaxAppNameLength = 48
+	syslogMaxMsgIdLength   = 32
+	syslogMaxTagLength     = 32
+)
+
+// Sockets of the local syslog daemon
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+var errSyslogReconnectWait = errors.New("syslog: waiting to reconnect")
+
+// Get the syslog severity of the level
+func syslogSeverity(level string) int {
+	switch level {
+	case "TRACE", "DEBUG":
+		return 7 // debug
+	case "WARN":
+		return 4 // warning
+	case "ERROR":
+		return 3 // err
+	case "FATAL":
+		return 2 // crit
+	case "PANIC":
+		return 1 // alert
+	default:
+		return 6 // info
+	}
+}
+
+// Get the header field with only printable ASCII (spaces are replaced with _), cut to the max length. "-" if empty
+func syslogHeaderField(s string, maxLen int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < maxLen; i++ {
+		c := s[i]
+		if c == ' ' {
+			b = append(b, '_')
+		} else if c > ' ' && c <= '~' {
+			b = append(b, c)
+		}
+	}
+
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// True for the stream based connections that need a framing
+func isSyslogStreamConn(conn net.Conn) bool {
+	switch conn.LocalAddr().Network() {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	default:
+		return false
+	}
+}
+
+// Get the MSG part of the syslog message. The terminal styles of the text styles are removed
+func (s *SyslogStream) content(msg message.LogMessage) string {
+	if s.option.LogStyle != "" {
+		return strings.TrimRight(style.StripTerminalStyle(style.GetMessageOfStyle(msg, s.option.LogStyle)), "\n")
+	}
+	return msg.Msg + style.FormatFields(msg.Fields)
+}
+
+// Get the syslog message in the format of the option
+func (s *SyslogStream) format(msg message.LogMessage) string {
+	pri := int(s.option.Facility)*8 + syslogSeverity(msg.Level)
+
+	if s.option.Format == SyslogRFC3164 {
+		content := s.content(msg)
+		if msg.Name != "" {
+			// No MSGID in RFC 3164, so the logger name is added to the content
+			content = "[" + msg.Name + "] " + content
+		}
+
+		// The local daemon adds the host name by itself
+		host := s.hostname + " "
+		if s.option.Network == "" {
+			host = ""
+		}
+
+		return fmt.Sprintf("<%d>%s %s%s[%d]: %s",
+			pri, msg.Time.Format(time.Stamp), host, syslogHeaderField(msg.AppName, syslogMaxTagLength), s.pid, content)
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s - %s",
+		pri,
+		msg.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname,
+		syslogHeaderField(msg.AppName, syslogMaxAppNameLength),
+		s.pid,
+		syslogHeaderField(msg.Name, syslogMaxMsgIdLength),
+		s.content(msg),
+	)
+}
+
+// Connect to the syslog server, or the local daemon if no network is given
+func (s *SyslogStream) dial() (net.Conn, error) {
+	if s.option.Network != "" {
+		return net.DialTimeout(s.option.Network, s.option.Address, s.option.Timeout)
+	}
+
+	var err error
+	for _, path := range localSyslogPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			if conn, err = net.DialTimeout(network, path, s.option.Timeout); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// Open the connection if not connected. Failed connections are not tried again before the reconnect interval
+func (s *SyslogStream) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+
+	if time.Now().Before(s.nextDial) {
+		return errSyslogReconnectWait
+	}
+
+	conn, err := s.dial()
+	if err != nil {
+		s.nextDial = time.Now().Add(s.option.ReconnectInterval)
+		return err
+	}
+
+	s.conn = conn
+	return nil
+}
+
+func (s *SyslogStream) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// Send the message with the framing of the connection
+func (s *SyslogStream) send(line string) error {
+	if err := s.connect(); err != nil {
+		return err
+	}
+
+	if isSyslogStreamConn(s.conn) {
+		if s.conn.LocalAddr().Network() == "unix" {
+			// Non-transparent framing (RFC 6587 3.4.2), as expected by the local daemons
+			line = strings.ReplaceAll(line, "\n", " ") + "\n"
+		} else {
+			// Octet counting (RFC 6587 3.4.1)
+			line = strconv.Itoa(len(line)) + " " + line
+		}
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(s.option.Timeout))
+	_, err := s.conn.Write([]byte(line))
+	return err
+}
+
+// Write the message to syslog. If the connection dropped, the message is sent again on a new connection
+func (s *SyslogStream) Write(msg message.LogMessage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		s.dropped++
+		return
+	}
+
+	line := s.format(msg)
+	if err := s.send(line); err != nil {
+		if err == errSyslogReconnectWait {
+			s.dropped++
+			return
+		}
+
+		s.disconnect()
+		if err := s.send(line); err != nil {
+			s.disconnect()
+			s.dropped++
+		}
+	}
+}
+
+// Number of messages dropped because syslog was not reachable or they were written after Close
+func (s *SyslogStream) Dropped() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.dropped
+}
+
+// Close the connection. Writes after Close are dropped
+func (s *SyslogStream) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func NewSyslogStream(option SyslogStreamOption) *SyslogStream {
+	if option.Facility <= 0 {
+		option.Facility = SyslogUser
+	}
+	if option.Timeout <= 0 {
+		option.Timeout = defaultSyslogTimeout
+	}
+	if option.ReconnectInterval <= 0 {
+		option.ReconnectInterval = defaultSyslogReconnectInterval
+	}
+
+	hostname := option.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	return &SyslogStream{
+		option:   option,
+		hostname: syslogHeaderField(hostname, 255),
+		pid:      os.Getpid(),
+		mutex:    &sync.Mutex{},
+	}
+}
@@ -0,0 +1,201 @@
+package stream_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/stream"
+	"github.com/jhseong7/ecl/style"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Read a datagram from the connection
+func readDatagram(conn net.PacketConn) string {
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	Expect(err).NotTo(HaveOccurred())
+	return string(buf[:n])
+}
+
+// Read an octet counted frame (RFC 6587) from the reader
+func readOctetCountedFrame(r *bufio.Reader) (string, error) {
+	length, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	return string(buf), err
+}
+
+var _ = Describe("Syslog Stream", func() {
+	It("Test RFC 5424 over UDP", func() {
+		server, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer server.Close()
+
+		s := stream.NewSyslogStream(stream.SyslogStreamOption{
+			Network:  "udp",
+			Address:  server.LocalAddr().String(),
+			Facility: stream.SyslogLocal0,
+			Hostname: "host-1",
+		})
+		defer s.Close()
+
+		msg := newTestMessage("hello")
+		msg.Level = "ERROR"
+		msg.AppName = "My App"
+		msg.Name = "Service"
+		msg.Fields = []message.Field{message.String("user", "alice")}
+		s.Write(msg)
+
+		// local0 (16) * 8 + err (3)
+		expected := fmt.Sprintf("<131>1 %s host-1 My_App %d Service - hello user=alice",
+			msg.Time.Format("2006-01-02T15:04:05.000000Z07:00"), os.Getpid())
+		Expect(readDatagram(server)).To(Equal(expected))
+
+		// Empty MSGID is "-"
+		msg.Level = "DEBUG"
+		msg.Name = ""
+		s.Write(msg)
+		Expect(readDatagram(server)).To(MatchRegexp(`^<135>1 .* My_App \d+ - - hello`))
+		Expect(s.Dropped()).To(BeZero())
+	})
+
+	It("Test RFC 3164 over unixgram", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "log.sock")
+		server, err := net.ListenPacket("unixgram", path)
+		Expect(err).NotTo(HaveOccurred())
+		defer server.Close()
+
+		s := stream.NewSyslogStream(stream.SyslogStreamOption{
+			Network:  "unixgram",
+			Address:  path,
+			Format:   stream.SyslogRFC3164,
+			Hostname: "host-1",
+		})
+		defer s.Close()
+
+		msg := newTestMessage("hello")
+		msg.Level = "WARN"
+		s.Write(msg)
+
+		// Default facility (user)
+		Expect(readDatagram(server)).To(Equal(fmt.Sprintf("<12>%s host-1 ECL[%d]: [test] hello",
+			msg.Time.Format(time.Stamp), os.Getpid())))
+	})
+
+	It("Test text style without terminal styles", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "log.sock")
+		server, err := net.ListenPacket("unixgram", path)
+		Expect(err).NotTo(HaveOccurred())
+		defer server.Close()
+
+		s := stream.NewSyslogStream(stream.SyslogStreamOption{
+			Network:  "unixgram",
+			Address:  path,
+			LogStyle: style.DefaultStyle,
+		})
+		defer s.Close()
+
+		msg := newTestMessage("hello")
+		msg.Color = style.Green
+		msg.Fields = []message.Field{message.String("user", "alice")}
+		s.Write(msg)
+
+		datagram := readDatagram(server)
+		Expect(datagram).NotTo(ContainSubstring("\x1b"))
+		Expect(datagram).To(ContainSubstring("hello user=alice"))
+	})
+
+	It("Test octet counting and reconnect over TCP", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		// Frames of all connections
+		frames := make(chan string, 100)
+		conns := make(chan net.Conn, 10)
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conns <- conn
+				go func() {
+					r := bufio.NewReader(conn)
+					for {
+						frame, err := readOctetCountedFrame(r)
+						if err != nil {
+							return
+						}
+						frames <- frame
+					}
+				}()
+			}
+		}()
+
+		s := stream.NewSyslogStream(stream.SyslogStreamOption{
+			Network: "tcp",
+			Address: listener.Addr().String(),
+		})
+		defer s.Close()
+
+		// Multi-line messages are kept in a single frame
+		s.Write(newTestMessage("first\nline"))
+		Eventually(frames).Should(Receive(HaveSuffix(" test - first\nline")))
+
+		// Drop the connection from the server side
+		(<-conns).Close()
+
+		// Writes on the dropped connection fail after a while, then the stream reconnects
+		frameRe := regexp.MustCompile(`^<14>1 .* ECL \d+ test - after$`)
+		Eventually(func() bool {
+			s.Write(newTestMessage("after"))
+			select {
+			case frame := <-frames:
+				return frameRe.MatchString(frame)
+			case <-time.After(50 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second).Should(BeTrue())
+		Expect(conns).To(HaveLen(1))
+
+		// Writes after Close are dropped
+		dropped := s.Dropped()
+		Expect(s.Close()).To(Succeed())
+		s.Write(newTestMessage("closed"))
+		Expect(s.Dropped()).To(Equal(dropped + 1))
+	})
+
+	It("Test unreachable server", func() {
+		s := stream.NewSyslogStream(stream.SyslogStreamOption{
+			Network:           "unix",
+			Address:           filepath.Join(GinkgoT().TempDir(), "missing.sock"),
+			ReconnectInterval: time.Hour,
+		})
+		defer s.Close()
+
+		s.Write(newTestMessage("m0"))
+		s.Write(newTestMessage("m1"))
+		Expect(s.Dropped()).To(BeEquivalentTo(2))
+	})
+})
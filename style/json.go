@@ -0,0 +1,191 @@
+package style
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+// Append the JSON encoded string. Uses encoding/json so the escaping is always valid
+func appendJsonString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+// Append the JSON value of the field, keeping the number and bool types
+func appendJsonFieldValue(buf *bytes.Buffer, f message.Field) {
+	switch f.Type {
+	case message.IntType:
+		if _, ok := f.Value.(int64); ok {
+			buf.WriteString(f.ValueString())
+			return
+		}
+	case message.BoolType:
+		if _, ok := f.Value.(bool); ok {
+			buf.WriteString(f.ValueString())
+			return
+		}
+	case message.FloatType:
+		// NaN and Inf are not valid JSON numbers
+		if v, ok := f.Value.(float64); ok && !math.IsNaN(v) && !math.IsInf(v, 0) {
+			buf.WriteString(f.ValueString())
+			return
+		}
+	case message.ObjectType:
+		// Try to keep the object structure, fallback to the string value
+		if b, err := json.Marshal(f.Value); err == nil {
+			buf.Write(b)
+			return
+		}
+	}
+
+	// Values not matching the type (e.g. a hand-built Field) are strings
+	appendJsonString(buf, f.ValueString())
+}
+
+// Keys set by the JSON style log. Fields with these keys are renamed to "fields.<key>" to avoid duplicate keys
+var jsonReservedKeys = map[string]bool{
+	"time":       true,
+	"level":      true,
+	"app":        true,
+	"logger":     true,
+	"msg":        true,
+	"pid":        true,
+	"trace_id":   true,
+	"span_id":    true,
+	"caller":     true,
+	"func":       true,
+	"stacktrace": true,
+}
+
+type (
+	// Field of the JSON style log with its unique key
+	jsonField struct {
+		key   string
+		field message.Field
+
+		// Cause chain of an error with causes, added as "<key>.chain"
+		chain []message.ErrorCause
+	}
+)
+
+// Get the fields with unique keys. A key given more than once (e.g. bound by With and given again) keeps the last value.
+// Fields with the reserved keys or the "<key>.chain" keys of the error chains are renamed to "fields.<key>"
+func jsonFields(fields []message.Field) []jsonField {
+	list := make([]jsonField, 0, len(fields))
+	index := map[string]int{}
+	chainKeys := map[string]bool{}
+
+	for _, f := range fields {
+		key := f.Key
+		if jsonReservedKeys[key] {
+			key = "fields." + key
+		}
+
+		jf := jsonField{key: key, field: f}
+		if err, ok := f.Value.(error); ok && f.Type == message.ErrorType {
+			if chain := message.ErrorChain(err); len(chain) > 1 {
+				jf.chain = chain
+			}
+		}
+
+		if i, ok := index[key]; ok {
+			list[i] = jf
+			continue
+		}
+		index[key] = len(list)
+		list = append(list, jf)
+	}
+
+	for _, jf := range list {
+		if jf.chain != nil {
+			chainKeys[jf.key+".chain"] = true
+		}
+	}
+
+	// Rename the fields colliding with the chain keys, dropping the ones that still collide after the rename
+	result := list[:0]
+	for _, jf := range list {
+		if chainKeys[jf.key] {
+			jf.key = "fields." + jf.key
+			if _, ok := index[jf.key]; ok {
+				continue
+			}
+		}
+		result = append(result, jf)
+	}
+
+	return result
+}
+
+// Get the JSON style log. One JSON object per line without any terminal styling
+func getJsonStyleLog(msg message.LogMessage) string {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"time":`)
+	appendJsonString(&buf, msg.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	appendJsonString(&buf, strings.ToLower(msg.Level))
+	buf.WriteString(`,"app":`)
+	appendJsonString(&buf, msg.AppName)
+	buf.WriteString(`,"logger":`)
+	appendJsonString(&buf, msg.Name)
+	buf.WriteString(`,"msg":`)
+	appendJsonString(&buf, msg.Msg)
+	buf.WriteString(`,"pid":`)
+	buf.WriteString(strconv.Itoa(os.Getpid()))
+
+	// Add the trace span IDs
+	if msg.TraceID != "" {
+		buf.WriteString(`,"trace_id":`)
+		appendJsonString(&buf, msg.TraceID)
+		buf.WriteString(`,"span_id":`)
+		appendJsonString(&buf, msg.SpanID)
+	}
+
+	// Add the caller if captured
+	if msg.Caller.Defined() {
+		buf.WriteString(`,"caller":`)
+		appendJsonString(&buf, msg.Caller.String())
+		buf.WriteString(`,"func":`)
+		appendJsonString(&buf, msg.Caller.Function)
+	}
+
+	// Add the stack trace if captured
+	if msg.Stacktrace != "" {
+		buf.WriteString(`,"stacktrace":`)
+		appendJsonString(&buf, msg.Stacktrace)
+	}
+
+	// Add the fields
+	for _, jf := range jsonFields(msg.Fields) {
+		buf.WriteString(",")
+		appendJsonString(&buf, jf.key)
+		buf.WriteString(":")
+		appendJsonFieldValue(&buf, jf.field)
+
+		// Add the cause chain of the errors with causes as "<key>.chain"
+		if jf.chain != nil {
+			buf.WriteString(",")
+			appendJsonString(&buf, jf.key+".chain")
+			buf.WriteString(":[")
+			for i, cause := range jf.chain {
+				if i > 0 {
+					buf.WriteString(",")
+				}
+				appendJsonString(&buf, cause.Msg)
+			}
+			buf.WriteString("]")
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.String()
+}
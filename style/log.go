@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/jhseong7/ecl/message"
 )
@@ -17,6 +19,8 @@ const (
 	DefaultStyle LogStyle = "DEFAULT"
 	NestJsStyle  LogStyle = "NESTJS"
 	SpringStyle  LogStyle = "SPRING"
+	JsonStyle    LogStyle = "JSON"
+	LogfmtStyle  LogStyle = "LOGFMT"
 )
 
 func colourize(color string, msg string) string {
@@ -55,6 +59,126 @@ func padCenter(s string, n int) string {
 	return s
 }
 
+// Quote the value if it cannot be read back as a single key=value token or has a non-printable character (e.g. ESC)
+func quoteFieldValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// Replace the characters that cannot be in a key (space, '=', '"' and the control characters) with '_'
+func sanitizeFieldKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+// Render the fields as a " key=value key=value" tail. Returns an empty string if there are no fields
+func formatFields(fields []message.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteString(" ")
+		sb.WriteString(sanitizeFieldKey(f.Key))
+		sb.WriteString("=")
+		sb.WriteString(quoteFieldValue(f.ValueString()))
+	}
+
+	return sb.String()
+}
+
+// Get the plain " key=value" tail of the fields. Used by the streams that build the message by themselves (e.g. syslog)
+func FormatFields(fields []message.Field) string {
+	return formatFields(fields)
+}
+
+// Get the coloured field tail. Empty if there are no fields so no stray escape codes are printed
+func colourizeFields(fields []message.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	return colourize(Gray, formatFields(fields))
+}
+
+// Get the coloured " file.go:42" of the caller. Empty if the caller is not captured
+func colourizeCaller(caller message.Caller) string {
+	if !caller.Defined() {
+		return ""
+	}
+	return " " + colourize(Gray, caller.String())
+}
+
+// Get the coloured cause chains of the error fields as a block indented under the log line.
+// Only the errors with causes (wrapped or joined) are shown, as a plain error is already in the field tail
+func colourizeErrorChains(fields []message.Field) string {
+	var sb strings.Builder
+
+	for _, f := range fields {
+		err, ok := f.Value.(error)
+		if f.Type != message.ErrorType || !ok {
+			continue
+		}
+
+		chain := message.ErrorChain(err)
+		if len(chain) < 2 {
+			continue
+		}
+
+		for _, cause := range chain {
+			indent := "    " + strings.Repeat("  ", cause.Depth)
+
+			sb.WriteString(indent)
+			if cause.Depth == 0 {
+				sb.WriteString(f.Key + ": ")
+			} else {
+				sb.WriteString("caused by: ")
+			}
+			sb.WriteString(strings.ReplaceAll(cause.Msg, "\n", "\n"+indent))
+			sb.WriteString("\n")
+		}
+	}
+
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	return colourize(Gray, sb.String())
+}
+
+// Get the coloured stack trace as a block indented under the log line. Empty if there is no stack trace
+func colourizeStacktrace(stacktrace string) string {
+	if stacktrace == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, line := range strings.Split(stacktrace, "\n") {
+		sb.WriteString("    ")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	return colourize(Gray, sb.String())
+}
+
+// Get the coloured " [trace_id/span_id]" of the trace span. Empty if there is no span
+func colourizeTrace(traceId, spanId string) string {
+	if traceId == "" {
+		return ""
+	}
+	return " " + colourize(Gray, "["+traceId+"/"+spanId+"]")
+}
+
 // Get the ECL default style log in string
 func getDefaultStyleLog(msg message.LogMessage) string {
 	pid := os.Getpid()
@@ -65,14 +189,17 @@ func getDefaultStyleLog(msg message.LogMessage) string {
 	}
 
 	return fmt.Sprintf(
-		"%s %s %s %s %s - %s\n", // Format string
+		"%s %s %s %s %s%s%s - %s%s\n",                                           // Format string
 		colourize(msg.Color, "| "+bold(padMinWidthRight(msg.AppName, 12)+" |")), // Set name of app (min 12 characters)
 		colourize(msg.Color, italic(padMinWidthRight(strconv.Itoa(pid), 6))),    // Add the process id
 		colourize(White, msg.Time.Format(time.RFC3339)),                         // Add the time (time is white)
 		colourize(msg.Color, bold(padMinWidthRight(msg.Level, 6))),              // Add the log level
 		colourize(Yellow, padMinWidthRight("["+msg.Name+"]", 20)),               // Add the log name (name of the logger is yellow)
+		colourizeCaller(msg.Caller),                                             // Add the caller (file.go:42)
+		colourizeTrace(msg.TraceID, msg.SpanID),                                 // Add the trace span IDs
 		colourize(msg.Color, msg.Msg),                                           // Add the message
-	)
+		colourizeFields(msg.Fields),                                             // Add the fields
+	) + colourizeErrorChains(msg.Fields) + colourizeStacktrace(msg.Stacktrace)
 }
 
 // Get the NestJS style log string
@@ -85,14 +212,17 @@ func getNestjsStyleLog(msg message.LogMessage) string {
 	}
 
 	return fmt.Sprintf(
-		"%s %-7s - %s %s %s %s\n",                                    // Format string
+		"%s %-7s - %s %s %s%s%s %s%s\n",                              // Format string
 		colourize(msg.Color, "["+msg.AppName+"]"),                    // Set colour
 		colourize(msg.Color, padMinWidthRight(strconv.Itoa(pid), 6)), // Add the process id
 		colourize(White, msg.Time.Format("01/02/2006, 3:04:05 PM")),  // Add the time (time is white)
 		colourize(msg.Color, padMinWidthLeft(msg.Level, 6)),          // Add the log level
 		colourize(Yellow, "["+msg.Name+"]"),                          // Add the log name (name of the logger is yellow)
+		colourizeCaller(msg.Caller),                                  // Add the caller (file.go:42)
+		colourizeTrace(msg.TraceID, msg.SpanID),                      // Add the trace span IDs
 		colourize(msg.Color, msg.Msg),                                // Add the message
-	)
+		colourizeFields(msg.Fields),                                  // Add the fields
+	) + colourizeErrorChains(msg.Fields) + colourizeStacktrace(msg.Stacktrace)
 }
 
 // Print Spring style log
@@ -100,6 +230,12 @@ func getSpringStyleLog(msg message.LogMessage) string {
 	pid := os.Getpid()
 	thread := "main" // Thread is always main
 
+	// The logger column is the caller's function if captured (like Logback's %C{1}.%M)
+	loggerName := msg.Name
+	if msg.Caller.Defined() {
+		loggerName = msg.Caller.ShortFunction()
+	}
+
 	timeStr := msg.Time.Format(time.RFC3339)
 
 	// Split the date-time
@@ -107,14 +243,15 @@ func getSpringStyleLog(msg message.LogMessage) string {
 	time := timeStr[11:]
 
 	return fmt.Sprintf(
-		"%s %s %s --- %s %s %s\n",                           // <date-time>  <log level> <process id> --- [<thread>] <logger> : <message>
+		"%s %s %s --- %s %s %s%s\n",                         // <date-time>  <log level> <process id> --- [<thread>] <logger> : <message>
 		colourize(White, date+" "+time),                     // Add the date-time (time is white)
 		colourize(msg.Color, padMinWidthLeft(msg.Level, 6)), // Add the log level
 		colourize(White, fmt.Sprintf("%d", pid)),            // Add the process id
 		colourize(Yellow, "["+thread+"]"),                   // Add the thread
-		colourize(Yellow, padMinWidthRight(msg.Name, 20)),   // Add the log name (name of the logger is yellow)
+		colourize(Yellow, padMinWidthRight(loggerName, 20)), // Add the log name (name of the logger is yellow)
 		colourize(msg.Color, msg.Msg),                       // Add the message
-	)
+		colourizeFields(msg.Fields),                         // Add the fields
+	) + colourizeErrorChains(msg.Fields) + colourizeStacktrace(msg.Stacktrace)
 }
 
 func GetMessageOfStyle(msg message.LogMessage, logStyle LogStyle) string {
@@ -124,6 +261,10 @@ func GetMessageOfStyle(msg message.LogMessage, logStyle LogStyle) string {
 		return getNestjsStyleLog(msg)
 	case SpringStyle:
 		return getSpringStyleLog(msg)
+	case JsonStyle:
+		return getJsonStyleLog(msg)
+	case LogfmtStyle:
+		return getLogfmtStyleLog(msg)
 	case DefaultStyle:
 		return getDefaultStyleLog(msg)
 	default:
@@ -0,0 +1,198 @@
+package style_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+	"github.com/jhseong7/ecl/style"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Log styles", func() {
+	msg := message.LogMessage{
+		AppName: "ECL",
+		Time:    time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC),
+		Name:    "OrderService",
+		Color:   style.Green,
+		Level:   "INFO",
+		Msg:     "order placed",
+		Fields: []message.Field{
+			message.String("user", "john doe"),
+			message.Int("count", 3),
+			message.Err(errors.New("boom")),
+		},
+	}
+
+	It("Test field tail in human styles", func() {
+		for _, s := range []style.LogStyle{style.DefaultStyle, style.NestJsStyle, style.SpringStyle} {
+			out := style.GetMessageOfStyle(msg, s)
+
+			Expect(out).To(ContainSubstring(`user="john doe"`))
+			Expect(out).To(ContainSubstring("count=3"))
+			Expect(out).To(ContainSubstring("error=boom"))
+		}
+	})
+
+	It("Test caller in human styles", func() {
+		m := msg
+		m.Caller = message.Caller{
+			File:     "/app/service/order.go",
+			Line:     42,
+			Function: "github.com/x/app/service.(*OrderService).checkout",
+		}
+
+		Expect(style.GetMessageOfStyle(m, style.DefaultStyle)).To(ContainSubstring("order.go:42"))
+		Expect(style.GetMessageOfStyle(m, style.NestJsStyle)).To(ContainSubstring("order.go:42"))
+
+		// Spring style shows the function in the logger column
+		Expect(style.GetMessageOfStyle(m, style.SpringStyle)).To(ContainSubstring("OrderService.checkout"))
+	})
+
+	It("Test stack trace block", func() {
+		m := msg
+		m.Stacktrace = "main.main()\n\t/app/main.go:42"
+
+		for _, s := range []style.LogStyle{style.DefaultStyle, style.NestJsStyle, style.SpringStyle} {
+			out := style.GetMessageOfStyle(m, s)
+			Expect(out).To(ContainSubstring("    main.main()\n    \t/app/main.go:42\n"))
+		}
+
+		Expect(style.GetMessageOfStyle(m, style.LogfmtStyle)).To(ContainSubstring(`stacktrace="main.main()\n\t/app/main.go:42"`))
+		Expect(style.GetMessageOfStyle(m, style.JsonStyle)).To(ContainSubstring(`"stacktrace":"main.main()\n\t/app/main.go:42"`))
+	})
+
+	It("Test error cause chain", func() {
+		m := msg
+		m.Fields = []message.Field{
+			message.Err(fmt.Errorf("checkout: %w", errors.Join(
+				errors.New("stock"),
+				fmt.Errorf("payment: %w", errors.New("card declined")),
+				errors.New("shipping"),
+			))),
+		}
+
+		out := style.GetMessageOfStyle(m, style.DefaultStyle)
+		Expect(out).To(ContainSubstring("    error: checkout: stock\n"))
+		Expect(out).To(ContainSubstring("      caused by: stock\n"))
+		Expect(out).To(ContainSubstring("      caused by: payment: card declined\n"))
+		Expect(out).To(ContainSubstring("        caused by: card declined\n"))
+		Expect(out).To(ContainSubstring("      caused by: shipping\n"))
+
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal([]byte(style.GetMessageOfStyle(m, style.JsonStyle)), &parsed)).To(Succeed())
+		Expect(parsed["error.chain"]).To(Equal([]interface{}{
+			"checkout: stock\npayment: card declined\nshipping",
+			"stock",
+			"payment: card declined",
+			"card declined",
+			"shipping",
+		}))
+
+		// A plain error has no chain
+		m.Fields = []message.Field{message.Err(errors.New("boom"))}
+		Expect(style.GetMessageOfStyle(m, style.JsonStyle)).NotTo(ContainSubstring("error.chain"))
+		Expect(style.GetMessageOfStyle(m, style.DefaultStyle)).NotTo(ContainSubstring("error: boom"))
+	})
+
+	It("Test trace span IDs", func() {
+		m := msg
+		m.TraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		m.SpanID = "00f067aa0ba902b7"
+
+		Expect(style.GetMessageOfStyle(m, style.DefaultStyle)).To(ContainSubstring("[4bf92f3577b34da6a3ce929d0e0e4736/00f067aa0ba902b7]"))
+		Expect(style.GetMessageOfStyle(m, style.NestJsStyle)).To(ContainSubstring("[4bf92f3577b34da6a3ce929d0e0e4736/00f067aa0ba902b7]"))
+		Expect(style.GetMessageOfStyle(m, style.LogfmtStyle)).To(ContainSubstring("trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7"))
+		Expect(style.GetMessageOfStyle(m, style.JsonStyle)).To(ContainSubstring(`"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"`))
+	})
+
+	It("Test JSON style", func() {
+		m := msg
+		m.Msg = "quote \" and \x1b[31mcolour"
+		out := style.GetMessageOfStyle(m, style.JsonStyle)
+
+		// One object per line without any terminal codes
+		Expect(out).To(HaveSuffix("}\n"))
+		Expect(out).NotTo(ContainSubstring("\x1b"))
+
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal([]byte(out), &parsed)).To(Succeed())
+
+		Expect(parsed["time"]).To(Equal("2026-10-18T12:30:00Z"))
+		Expect(parsed["level"]).To(Equal("info"))
+		Expect(parsed["app"]).To(Equal("ECL"))
+		Expect(parsed["logger"]).To(Equal("OrderService"))
+		Expect(parsed["msg"]).To(Equal(m.Msg))
+		Expect(parsed["pid"]).To(BeNumerically(">", 0))
+		Expect(parsed["user"]).To(Equal("john doe"))
+		Expect(parsed["count"]).To(BeNumerically("==", 3))
+		Expect(parsed["error"]).To(Equal("boom"))
+	})
+
+	It("Test JSON style reserved keys", func() {
+		m := msg
+		m.Fields = []message.Field{message.String("msg", "field"), message.String("level", "debug"), message.Err(errors.New("boom"))}
+		out := style.GetMessageOfStyle(m, style.JsonStyle)
+
+		// Duplicate keys would be silently overwritten by the parser
+		Expect(out).To(ContainSubstring(`"msg":"order placed"`))
+		Expect(out).NotTo(ContainSubstring(`"msg":"field"`))
+
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal([]byte(out), &parsed)).To(Succeed())
+		Expect(parsed["msg"]).To(Equal("order placed"))
+		Expect(parsed["level"]).To(Equal("info"))
+		Expect(parsed["fields.msg"]).To(Equal("field"))
+		Expect(parsed["fields.level"]).To(Equal("debug"))
+		Expect(parsed["error"]).To(Equal("boom"))
+	})
+
+	It("Test JSON style duplicate keys", func() {
+		m := msg
+		m.Fields = []message.Field{
+			message.String("user", "a"),
+			message.String("error.chain", "field"),
+			message.Err(fmt.Errorf("save: %w", errors.New("boom"))),
+			message.String("user", "b"),
+		}
+		out := style.GetMessageOfStyle(m, style.JsonStyle)
+
+		// Each key is written once. The last value of a repeated key wins
+		Expect(strings.Count(out, `"user":`)).To(Equal(1))
+		Expect(strings.Count(out, `"error.chain":`)).To(Equal(1))
+
+		var parsed map[string]interface{}
+		Expect(json.Unmarshal([]byte(out), &parsed)).To(Succeed())
+		Expect(parsed["user"]).To(Equal("b"))
+		Expect(parsed["error.chain"]).To(Equal([]interface{}{"save: boom", "boom"}))
+		Expect(parsed["fields.error.chain"]).To(Equal("field"))
+	})
+
+	It("Test logfmt style", func() {
+		m := msg
+		m.Msg = "say \"hi\"\nbye"
+		out := style.GetMessageOfStyle(m, style.LogfmtStyle)
+
+		Expect(out).To(Equal(
+			`time=2026-10-18T12:30:00Z level=info app=ECL logger=OrderService msg="say \"hi\"\nbye" user="john doe" count=3 error=boom` + "\n",
+		))
+
+		// Invalid key characters are replaced so each pair stays a single token
+		m.Fields = []message.Field{message.String("user name", "john"), message.Int("a=b", 1), message.Bool("\"q\"\n", true)}
+		Expect(style.GetMessageOfStyle(m, style.LogfmtStyle)).To(HaveSuffix(` user_name=john a_b=1 _q__=true` + "\n"))
+
+		// Fields do not replace the keys of the log, and the non-printable characters are quoted
+		m.Fields = []message.Field{message.String("msg", "field"), message.String("esc", "a\x1b[31mb")}
+		Expect(style.GetMessageOfStyle(m, style.LogfmtStyle)).To(HaveSuffix(` fields.msg=field esc="a\x1b[31mb"` + "\n"))
+	})
+})
+
+func TestStyle(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Style Suite")
+}
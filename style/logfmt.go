@@ -0,0 +1,76 @@
+package style
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jhseong7/ecl/message"
+)
+
+// Keys set by the logfmt style log. Fields with these keys are renamed to "fields.<key>", as the parsers keep the last value of a key
+var logfmtReservedKeys = map[string]bool{
+	"time":       true,
+	"level":      true,
+	"app":        true,
+	"logger":     true,
+	"msg":        true,
+	"trace_id":   true,
+	"span_id":    true,
+	"caller":     true,
+	"stacktrace": true,
+}
+
+// Get the fields with the reserved keys renamed
+func logfmtFields(fields []message.Field) []message.Field {
+	renamed := make([]message.Field, len(fields))
+	for i, f := range fields {
+		if logfmtReservedKeys[f.Key] {
+			f.Key = "fields." + f.Key
+		}
+		renamed[i] = f
+	}
+	return renamed
+}
+
+// Get the logfmt style log. key=value pairs without any terminal styling
+func getLogfmtStyleLog(msg message.LogMessage) string {
+	var sb strings.Builder
+
+	sb.WriteString("time=")
+	sb.WriteString(msg.Time.Format(time.RFC3339Nano))
+	sb.WriteString(" level=")
+	sb.WriteString(quoteFieldValue(strings.ToLower(msg.Level)))
+	sb.WriteString(" app=")
+	sb.WriteString(quoteFieldValue(msg.AppName))
+	sb.WriteString(" logger=")
+	sb.WriteString(quoteFieldValue(msg.Name))
+	sb.WriteString(" msg=")
+	sb.WriteString(quoteFieldValue(msg.Msg))
+
+	// Add the trace span IDs
+	if msg.TraceID != "" {
+		sb.WriteString(" trace_id=")
+		sb.WriteString(quoteFieldValue(msg.TraceID))
+		sb.WriteString(" span_id=")
+		sb.WriteString(quoteFieldValue(msg.SpanID))
+	}
+
+	// Add the caller if captured
+	if msg.Caller.Defined() {
+		sb.WriteString(" caller=")
+		sb.WriteString(quoteFieldValue(msg.Caller.String()))
+	}
+
+	// Add the fields
+	sb.WriteString(formatFields(logfmtFields(msg.Fields)))
+
+	// Add the stack trace if captured
+	if msg.Stacktrace != "" {
+		sb.WriteString(" stacktrace=")
+		sb.WriteString(quoteFieldValue(msg.Stacktrace))
+	}
+
+	sb.WriteString("\n")
+
+	return sb.String()
+}
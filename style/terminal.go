@@ -1,5 +1,7 @@
 package style
 
+import "regexp"
+
 // Terminal styles
 const (
 	// Reset Codes
@@ -56,3 +58,10 @@ const (
 	Strike          = "\033[9m"
 	DoubleUnderline = "\033[21m"
 )
+
+var terminalStyleRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// Remove the terminal styles from the log. Used by the streams that write to the outputs other than a terminal
+func StripTerminalStyle(log string) string {
+	return terminalStyleRegex.ReplaceAllString(log, "")
+}